@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AccountType selects which Policy governs an account's deposits,
+// withdrawals and interest accrual. It is fixed at OpenAccount time.
+type AccountType string
+
+const (
+	Checking     AccountType = "CHECKING"
+	Savings      AccountType = "SAVINGS"
+	MoneyMarket  AccountType = "MONEY_MARKET"
+	LineOfCredit AccountType = "LINE_OF_CREDIT"
+	Loan         AccountType = "LOAN"
+)
+
+var ErrUnknownAccountType = errors.New("неизвестный тип счёта")
+
+// Policy encapsulates the product-specific rules that used to be
+// hard-coded into AccountServiceImpl.Deposit/Withdraw. Each AccountType
+// resolves to exactly one Policy via PolicyFor.
+type Policy interface {
+	// ValidateInitialDeposit runs once, at OpenAccount time, before the
+	// account is ever persisted.
+	ValidateInitialDeposit(amount Money) error
+	ValidateDeposit(account *Account, amount Money) error
+	ValidateWithdraw(account *Account, amount Money) error
+	// AccrueInterest reports the interest due on account as of now. ok is
+	// false when the policy never accrues interest, or when not enough
+	// time has passed since the last "interest" transaction in its
+	// history. Applying the returned amount (appending the transaction,
+	// updating the balance, persisting) is the caller's job; see
+	// interest_scheduler.go.
+	AccrueInterest(account *Account, now time.Time) (interest Money, ok bool)
+}
+
+// PolicyFor returns the Policy for accountType, or ErrUnknownAccountType if
+// accountType is not one of the constants defined above.
+func PolicyFor(accountType AccountType) (Policy, error) {
+	switch accountType {
+	case Checking:
+		return checkingPolicy{}, nil
+	case Savings:
+		return savingsPolicy{}, nil
+	case MoneyMarket:
+		return moneyMarketPolicy{}, nil
+	case LineOfCredit:
+		return lineOfCreditPolicy{}, nil
+	case Loan:
+		return loanPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAccountType, accountType)
+	}
+}
+
+// isDepositProduct reports whether accountType is funded by the customer
+// (as opposed to a credit product the bank extends to the customer).
+func isDepositProduct(accountType AccountType) bool {
+	switch accountType {
+	case Checking, Savings, MoneyMarket:
+		return true
+	default:
+		return false
+	}
+}
+
+func lastInterestAt(account *Account) (time.Time, bool) {
+	for i := len(account.History) - 1; i >= 0; i-- {
+		if account.History[i].Type == "interest" {
+			return account.History[i].Timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ---- CHECKING: no special rules beyond the funds check every account gets. ----
+
+type checkingPolicy struct{}
+
+func (checkingPolicy) ValidateInitialDeposit(amount Money) error {
+	if amount.Amount < 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func (checkingPolicy) ValidateDeposit(account *Account, amount Money) error { return nil }
+
+func (checkingPolicy) ValidateWithdraw(account *Account, amount Money) error {
+	if amount.Amount > account.Balance {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (checkingPolicy) AccrueInterest(account *Account, now time.Time) (Money, bool) {
+	return Money{}, false
+}
+
+// ---- SAVINGS: like checking, but accrues interest monthly. ----
+
+const savingsMonthlyRate = 0.002 // 0.2% per month, nominal
+
+type savingsPolicy struct{}
+
+func (savingsPolicy) ValidateInitialDeposit(amount Money) error {
+	if amount.Amount < 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func (savingsPolicy) ValidateDeposit(account *Account, amount Money) error { return nil }
+
+func (savingsPolicy) ValidateWithdraw(account *Account, amount Money) error {
+	if amount.Amount > account.Balance {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (savingsPolicy) AccrueInterest(account *Account, now time.Time) (Money, bool) {
+	since, hasAccrued := lastInterestAt(account)
+	if hasAccrued && now.Sub(since) < 30*24*time.Hour {
+		return Money{}, false
+	}
+	if account.Balance <= 0 {
+		return Money{}, false
+	}
+	interest := int64(float64(account.Balance) * savingsMonthlyRate)
+	if interest <= 0 {
+		return Money{}, false
+	}
+	return Money{Amount: interest, Currency: account.Currency}, true
+}
+
+// ---- MONEY_MARKET: like savings, but enforces a minimum balance. ----
+
+const moneyMarketMinBalance = 100000 // minor units of the account's currency
+const moneyMarketMonthlyRate = 0.004
+
+var ErrBelowMinimumBalance = errors.New("операция нарушает минимальный остаток по счёту")
+
+type moneyMarketPolicy struct{}
+
+func (moneyMarketPolicy) ValidateInitialDeposit(amount Money) error {
+	if amount.Amount < 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func (moneyMarketPolicy) ValidateDeposit(account *Account, amount Money) error { return nil }
+
+func (moneyMarketPolicy) ValidateWithdraw(account *Account, amount Money) error {
+	if amount.Amount > account.Balance {
+		return ErrInsufficientFunds
+	}
+	if account.Balance-amount.Amount < moneyMarketMinBalance {
+		return ErrBelowMinimumBalance
+	}
+	return nil
+}
+
+func (moneyMarketPolicy) AccrueInterest(account *Account, now time.Time) (Money, bool) {
+	since, hasAccrued := lastInterestAt(account)
+	if hasAccrued && now.Sub(since) < 30*24*time.Hour {
+		return Money{}, false
+	}
+	if account.Balance <= 0 {
+		return Money{}, false
+	}
+	interest := int64(float64(account.Balance) * moneyMarketMonthlyRate)
+	if interest <= 0 {
+		return Money{}, false
+	}
+	return Money{Amount: interest, Currency: account.Currency}, true
+}
+
+// ---- LINE_OF_CREDIT: balance may go negative, down to -creditLimit. ----
+
+const lineOfCreditLimit = 500000 // minor units of the account's currency
+
+var ErrCreditLimitExceeded = errors.New("превышен лимит кредитной линии")
+
+type lineOfCreditPolicy struct{}
+
+func (lineOfCreditPolicy) ValidateInitialDeposit(amount Money) error {
+	if amount.Amount != 0 {
+		return fmt.Errorf("%w: кредитный счёт открывается с нулевым депозитом", ErrInvalidAmount)
+	}
+	return nil
+}
+
+func (lineOfCreditPolicy) ValidateDeposit(account *Account, amount Money) error { return nil }
+
+func (lineOfCreditPolicy) ValidateWithdraw(account *Account, amount Money) error {
+	if account.Balance-amount.Amount < -lineOfCreditLimit {
+		return ErrCreditLimitExceeded
+	}
+	return nil
+}
+
+func (lineOfCreditPolicy) AccrueInterest(account *Account, now time.Time) (Money, bool) {
+	return Money{}, false
+}
+
+// ---- LOAN: principal never enters through Deposit (ValidateInitialDeposit
+// forces a zero opening balance, same as LINE_OF_CREDIT); it is disbursed
+// separately by BankingService.DisburseLoan, which drives Balance negative
+// by the amount owed. Once disbursed, every Deposit is a payment against
+// that debt, capped so a payment can't overpay past zero, and Withdraw is
+// never permitted. ----
+
+var ErrLoanWithdrawalNotPermitted = errors.New("снятие средств с кредитного счёта (LOAN) не допускается")
+var ErrNoOutstandingLoanBalance = errors.New("по кредитному счёту нет задолженности для погашения")
+var ErrLoanPaymentExceedsBalance = errors.New("сумма платежа превышает остаток задолженности")
+var ErrAccountNotLoan = errors.New("счёт не является кредитным (LOAN)")
+var ErrLoanAlreadyDisbursed = errors.New("кредит по счёту уже выдан")
+
+type loanPolicy struct{}
+
+func (loanPolicy) ValidateInitialDeposit(amount Money) error {
+	if amount.Amount != 0 {
+		return fmt.Errorf("%w: кредитный счёт открывается с нулевым депозитом, кредит выдаётся операцией DisburseLoan", ErrInvalidAmount)
+	}
+	return nil
+}
+
+// ValidateDeposit treats every deposit as a payment against the principal
+// disbursed by DisburseLoan: it is rejected once nothing is owed (Balance
+// == 0, whether never disbursed or already paid off) and capped so a
+// payment can't carry the balance past zero.
+func (loanPolicy) ValidateDeposit(account *Account, amount Money) error {
+	if account.Balance >= 0 {
+		return ErrNoOutstandingLoanBalance
+	}
+	if amount.Amount > -account.Balance {
+		return ErrLoanPaymentExceedsBalance
+	}
+	return nil
+}
+
+func (loanPolicy) ValidateWithdraw(account *Account, amount Money) error {
+	return ErrLoanWithdrawalNotPermitted
+}
+
+func (loanPolicy) AccrueInterest(account *Account, now time.Time) (Money, bool) {
+	return Money{}, false
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BankingService holds all business logic for the application. It has no
+// knowledge of how it is being driven: both the interactive CLI and the
+// gRPC server (see grpc_server.go) are thin wrappers around it.
+type BankingService struct {
+	storage  Storage
+	transfer *TransferRunner
+
+	mu          sync.Mutex
+	subscribers map[string][]chan Transaction
+
+	// accountLocks serializes each account's load-mutate-save sequence
+	// (Deposit/Withdraw) against concurrent callers, e.g. two simultaneous
+	// gRPC requests against the same account. Transfer doesn't need this:
+	// it's already serialized per-account by the saga's own idempotent,
+	// compare-and-swap-backed legs.
+	accountLocks sync.Map
+}
+
+// NewBankingService wires up a BankingService backed by storage. rates
+// resolves exchange rates for cross-currency transfers. Transfer sagas are
+// kept in a new InMemorySagaStore, so the RecoverSagas call here only scans
+// that empty store: a saga left non-terminal by a previous process (e.g.
+// one that crashed mid-transfer) is not recovered.
+func NewBankingService(storage Storage, rates RateProvider) *BankingService {
+	b := &BankingService{
+		storage:     storage,
+		subscribers: make(map[string][]chan Transaction),
+	}
+	b.transfer = NewTransferRunner(storage, NewInMemorySagaStore(), rates)
+	b.transfer.onLeg = func(accountID string, txn Transaction) {
+		b.publish(&Account{ID: accountID}, txn)
+	}
+	if err := b.transfer.RecoverSagas(context.Background()); err != nil {
+		fmt.Printf("Ошибка восстановления саг перевода: %v\n", err)
+	}
+	return b
+}
+
+// OpenAccount creates accountType's product with an optional initial
+// deposit, validated against that product's Policy (e.g. credit products
+// must be opened with a zero balance).
+func (b *BankingService) OpenAccount(owner, currency string, accountType AccountType, initialDeposit Money) (*Account, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("имя владельца не может быть пустым")
+	}
+	if currency == "" {
+		return nil, fmt.Errorf("валюта счёта не может быть пустой")
+	}
+	policy, err := PolicyFor(accountType)
+	if err != nil {
+		return nil, err
+	}
+	if initialDeposit.Amount != 0 && initialDeposit.Currency != currency {
+		return nil, ErrCurrencyMismatch
+	}
+	if err := policy.ValidateInitialDeposit(initialDeposit); err != nil {
+		return nil, err
+	}
+	account := &Account{
+		Owner:    owner,
+		Currency: currency,
+		Type:     accountType,
+		Balance:  initialDeposit.Amount,
+		History:  []Transaction{},
+	}
+	if initialDeposit.Amount > 0 {
+		account.History = append(account.History, Transaction{
+			ID:        newTxnID(),
+			Type:      "deposit",
+			Amount:    initialDeposit.Amount,
+			Currency:  currency,
+			Timestamp: time.Now(),
+		})
+	}
+	if err := b.storage.SaveAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// CloseAccount refuses to remove an account with a non-zero balance: for
+// deposit products that would destroy customer funds, and for credit
+// products it would hide an outstanding debt.
+func (b *BankingService) CloseAccount(accountID string) error {
+	account, err := b.storage.LoadAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if account.Balance != 0 {
+		return ErrNonZeroBalance
+	}
+	return b.storage.DeleteAccount(accountID)
+}
+
+// accountLock returns the mutex guarding accountID's load-mutate-save
+// sequence, creating one on first use.
+func (b *BankingService) accountLock(accountID string) *sync.Mutex {
+	lock, _ := b.accountLocks.LoadOrStore(accountID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+func (b *BankingService) Deposit(accountID string, amount Money) (*Account, error) {
+	lock := b.accountLock(accountID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	account, err := b.storage.LoadAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	svc := NewAccountService(account, b.storage)
+	if err := svc.Deposit(amount); err != nil {
+		return nil, err
+	}
+	b.publish(account, account.History[len(account.History)-1])
+	return account, nil
+}
+
+func (b *BankingService) Withdraw(accountID string, amount Money) (*Account, error) {
+	lock := b.accountLock(accountID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	account, err := b.storage.LoadAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	svc := NewAccountService(account, b.storage)
+	if err := svc.Withdraw(amount); err != nil {
+		return nil, err
+	}
+	b.publish(account, account.History[len(account.History)-1])
+	return account, nil
+}
+
+// DisburseLoan is the only way principal enters a LOAN account:
+// ValidateInitialDeposit forces it to open at zero and
+// loanPolicy.ValidateDeposit treats every Deposit as a payment, so without
+// this bank-initiated operation a LOAN account could never actually owe
+// anything. It drives Balance to -principal.Amount and refuses to run
+// while Balance is non-zero, so a second disbursement is refused until the
+// first has been repaid in full.
+func (b *BankingService) DisburseLoan(accountID string, principal Money) (*Account, error) {
+	lock := b.accountLock(accountID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	account, err := b.storage.LoadAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Type != Loan {
+		return nil, ErrAccountNotLoan
+	}
+	if account.Balance != 0 {
+		return nil, ErrLoanAlreadyDisbursed
+	}
+	if principal.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if principal.Currency != account.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	txn := Transaction{
+		ID:        newTxnID(),
+		Type:      "loan_disbursement",
+		Amount:    principal.Amount,
+		Currency:  principal.Currency,
+		Timestamp: time.Now(),
+	}
+	account.Balance -= principal.Amount
+	account.History = append(account.History, txn)
+	if err := b.storage.SaveAccount(account); err != nil {
+		return nil, err
+	}
+	b.publish(account, txn)
+	return account, nil
+}
+
+// Transfer runs a transfer as a saga (see saga.go) and blocks until it
+// reaches a terminal state, so existing callers keep their synchronous
+// request/response shape. Callers that want to fire-and-forget can use
+// TransferAsync/WaitForTransfer directly instead.
+func (b *BankingService) Transfer(fromID, toID string, amount Money) (*Account, error) {
+	referenceID, err := b.TransferAsync(fromID, toID, amount)
+	if err != nil {
+		return nil, err
+	}
+	saga, err := b.WaitForTransfer(context.Background(), referenceID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status == SagaFailed {
+		return nil, fmt.Errorf("перевод не выполнен: %s", saga.LastError)
+	}
+	return b.storage.LoadAccount(fromID)
+}
+
+// TransferAsync starts a transfer saga and returns its reference ID without
+// waiting for completion.
+func (b *BankingService) TransferAsync(fromID, toID string, amount Money) (string, error) {
+	return b.transfer.TransferAsync(fromID, toID, amount)
+}
+
+// WaitForTransfer blocks until the saga identified by referenceID reaches a
+// terminal state.
+func (b *BankingService) WaitForTransfer(ctx context.Context, referenceID string) (*TransferSaga, error) {
+	return b.transfer.WaitForTransfer(ctx, referenceID)
+}
+
+func (b *BankingService) GetStatement(accountID string) (string, error) {
+	account, err := b.storage.LoadAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+	return NewAccountService(account, b.storage).GetStatement(), nil
+}
+
+func (b *BankingService) ListAccounts() ([]*Account, error) {
+	return b.storage.GetAllAccounts()
+}
+
+// ListAccountsByOwner is ListAccounts scoped to a single owner, for callers
+// (the gRPC server) that must not leak other customers' accounts.
+func (b *BankingService) ListAccountsByOwner(owner string) ([]*Account, error) {
+	all, err := b.storage.GetAllAccounts()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*Account, 0, len(all))
+	for _, acc := range all {
+		if acc.Owner == owner {
+			accounts = append(accounts, acc)
+		}
+	}
+	return accounts, nil
+}
+
+// ExecuteScript compiles and runs a ledger script (see ledger_script.go)
+// against this service's storage, atomically applying whatever postings
+// it produces.
+func (b *BankingService) ExecuteScript(source string, vars map[string]any) ([]Posting, error) {
+	postings, err := ExecuteScript(b.storage, source, vars)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range postings {
+		if account, loadErr := b.storage.LoadAccount(p.Source); loadErr == nil {
+			b.publish(account, account.History[len(account.History)-1])
+		}
+		if account, loadErr := b.storage.LoadAccount(p.Destination); loadErr == nil {
+			b.publish(account, account.History[len(account.History)-1])
+		}
+	}
+	return postings, nil
+}
+
+// Subscribe registers a channel that receives every new transaction posted
+// to accountID, for WatchTransactions. The caller is responsible for
+// draining the channel; Unsubscribe must be called when done.
+func (b *BankingService) Subscribe(accountID string) chan Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Transaction, 16)
+	b.subscribers[accountID] = append(b.subscribers[accountID], ch)
+	return ch
+}
+
+func (b *BankingService) Unsubscribe(accountID string, ch chan Transaction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := b.subscribers[accountID]
+	for i, c := range subs {
+		if c == ch {
+			b.subscribers[accountID] = append(subs[:i], subs[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+func (b *BankingService) publish(account *Account, txn Transaction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subscribers[account.ID] {
+		select {
+		case ch <- txn:
+		default:
+			// Slow subscriber; drop rather than block the caller.
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// customerMetadataKey is the gRPC metadata header clients set to identify
+// themselves; its value is treated as the calling owner's identity.
+const customerMetadataKey = "customer"
+
+// AuthUnaryInterceptor rejects any call that doesn't carry a "customer"
+// metadata header, so handlers can rely on callerOwner(ctx) downstream.
+func AuthUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if _, err := callerOwner(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthStreamInterceptor is the streaming-RPC equivalent of AuthUnaryInterceptor.
+func AuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if _, err := callerOwner(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func callerOwner(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "отсутствуют метаданные запроса")
+	}
+	values := md.Get(customerMetadataKey)
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+		return "", status.Error(codes.Unauthenticated, "отсутствует заголовок customer")
+	}
+	return values[0], nil
+}
+
+// requireOwnership checks that the authenticated caller owns accountID
+// before a handler touches it.
+func requireOwnership(ctx context.Context, svc *BankingService, accountID string) error {
+	owner, err := callerOwner(ctx)
+	if err != nil {
+		return err
+	}
+	account, loadErr := svc.storage.LoadAccount(accountID)
+	if loadErr != nil {
+		return translateError(loadErr)
+	}
+	if account.Owner != owner {
+		return status.Error(codes.PermissionDenied, "счёт принадлежит другому клиенту")
+	}
+	return nil
+}
+
+func splitStatementLines(statement string) []string {
+	lines := strings.Split(statement, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func joinStatementLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
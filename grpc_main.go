@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+
+	bankpb "github.com/whruii/Banking-Application/proto"
+)
+
+func defaultGRPCAddr() string {
+	if addr := os.Getenv("BANK_GRPC_ADDR"); addr != "" {
+		return addr
+	}
+	return ":50051"
+}
+
+// ServeGRPC starts the Bank gRPC server and blocks until it stops serving
+// or the listener fails. Every RPC runs through AuthUnaryInterceptor /
+// AuthStreamInterceptor, which require the "customer" metadata header.
+func ServeGRPC(addr string, svc *BankingService) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("прослушивание %s: %w", addr, err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(AuthUnaryInterceptor),
+		grpc.StreamInterceptor(AuthStreamInterceptor),
+	)
+	bankpb.RegisterBankServer(grpcServer, NewGRPCServer(svc))
+
+	fmt.Printf("gRPC-сервер запущен на %s\n", addr)
+	return grpcServer.Serve(lis)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bankpb "github.com/whruii/Banking-Application/proto"
+)
+
+// statementPageSize bounds how many lines of the rendered statement
+// GetStatement returns per page when the caller doesn't specify page_size.
+// The statement's header lines count toward the first page.
+const statementPageSize = 50
+
+// grpcServer implements bankpb.BankServer on top of BankingService,
+// translating domain errors into gRPC status codes.
+type grpcServer struct {
+	bankpb.UnimplementedBankServer
+	svc *BankingService
+}
+
+func NewGRPCServer(svc *BankingService) bankpb.BankServer {
+	return &grpcServer{svc: svc}
+}
+
+func (s *grpcServer) OpenAccount(ctx context.Context, req *bankpb.OpenAccountRequest) (*bankpb.Account, error) {
+	account, err := s.svc.OpenAccount(
+		req.GetOwner(),
+		req.GetCurrency(),
+		AccountType(req.GetAccountType()),
+		Money{Amount: req.GetInitialDeposit(), Currency: req.GetCurrency()},
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return toProtoAccount(account), nil
+}
+
+func (s *grpcServer) CloseAccount(ctx context.Context, req *bankpb.CloseAccountRequest) (*bankpb.CloseAccountResponse, error) {
+	if err := requireOwnership(ctx, s.svc, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	if err := s.svc.CloseAccount(req.GetAccountId()); err != nil {
+		return nil, translateError(err)
+	}
+	return &bankpb.CloseAccountResponse{}, nil
+}
+
+func (s *grpcServer) Deposit(ctx context.Context, req *bankpb.DepositRequest) (*bankpb.Account, error) {
+	if err := requireOwnership(ctx, s.svc, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	account, err := s.svc.Deposit(req.GetAccountId(), Money{Amount: req.GetAmount(), Currency: req.GetCurrency()})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return toProtoAccount(account), nil
+}
+
+func (s *grpcServer) Withdraw(ctx context.Context, req *bankpb.WithdrawRequest) (*bankpb.Account, error) {
+	if err := requireOwnership(ctx, s.svc, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	account, err := s.svc.Withdraw(req.GetAccountId(), Money{Amount: req.GetAmount(), Currency: req.GetCurrency()})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return toProtoAccount(account), nil
+}
+
+func (s *grpcServer) Transfer(ctx context.Context, req *bankpb.TransferRequest) (*bankpb.Account, error) {
+	if err := requireOwnership(ctx, s.svc, req.GetFromAccountId()); err != nil {
+		return nil, err
+	}
+	account, err := s.svc.Transfer(req.GetFromAccountId(), req.GetToAccountId(), Money{Amount: req.GetAmount(), Currency: req.GetCurrency()})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return toProtoAccount(account), nil
+}
+
+// GetStatement paginates the account's rendered statement line by line.
+// page_token is the index of the first statement line to return, encoded
+// as a decimal string; an empty token starts from the beginning.
+func (s *grpcServer) GetStatement(ctx context.Context, req *bankpb.GetStatementRequest) (*bankpb.GetStatementResponse, error) {
+	if err := requireOwnership(ctx, s.svc, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	statement, err := s.svc.GetStatement(req.GetAccountId())
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = statementPageSize
+	}
+	start := 0
+	if req.GetPageToken() != "" {
+		start, err = strconv.Atoi(req.GetPageToken())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "некорректный page_token: %v", err)
+		}
+	}
+
+	lines := splitStatementLines(statement)
+	end := start + pageSize
+	if end > len(lines) {
+		end = len(lines)
+	}
+	resp := &bankpb.GetStatementResponse{}
+	if start < len(lines) {
+		resp.Statement = joinStatementLines(lines[start:end])
+	}
+	if end < len(lines) {
+		resp.NextPageToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+// ListAccounts is scoped to the authenticated caller: it must never return
+// another customer's accounts, so it ignores any owner the request might
+// try to specify and uses the identity the "customer" metadata header
+// already establishes (see grpc_auth.go), same as every other RPC here.
+func (s *grpcServer) ListAccounts(ctx context.Context, req *bankpb.ListAccountsRequest) (*bankpb.ListAccountsResponse, error) {
+	owner, err := callerOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.svc.ListAccountsByOwner(owner)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	resp := &bankpb.ListAccountsResponse{}
+	for _, acc := range accounts {
+		resp.Accounts = append(resp.Accounts, toProtoAccount(acc))
+	}
+	return resp, nil
+}
+
+func (s *grpcServer) WatchTransactions(req *bankpb.WatchTransactionsRequest, stream bankpb.Bank_WatchTransactionsServer) error {
+	if err := requireOwnership(stream.Context(), s.svc, req.GetAccountId()); err != nil {
+		return err
+	}
+	ch := s.svc.Subscribe(req.GetAccountId())
+	defer s.svc.Unsubscribe(req.GetAccountId(), ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case txn, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toProtoTransaction(txn)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoAccount(a *Account) *bankpb.Account {
+	return &bankpb.Account{Id: a.ID, Owner: a.Owner, Balance: a.Balance, Currency: a.Currency, AccountType: string(a.Type)}
+}
+
+func toProtoTransaction(t Transaction) *bankpb.Transaction {
+	return &bankpb.Transaction{
+		Id:                t.ID,
+		Type:              t.Type,
+		Amount:            t.Amount,
+		Currency:          t.Currency,
+		Timestamp:         timestamppb.New(t.Timestamp),
+		ToFrom:            t.ToFrom,
+		RateApplied:       t.RateApplied,
+		ConvertedAmount:   t.ConvertedAmount,
+		ConvertedCurrency: t.ConvertedCurrency,
+	}
+}
+
+// translateError maps domain sentinel errors to the gRPC status codes
+// clients are expected to branch on.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidAmount), errors.Is(err, ErrSameAccountTransfer), errors.Is(err, ErrCurrencyMismatch), errors.Is(err, ErrUnknownAccountType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrInsufficientFunds), errors.Is(err, ErrNonZeroBalance), errors.Is(err, ErrBelowMinimumBalance),
+		errors.Is(err, ErrCreditLimitExceeded), errors.Is(err, ErrLoanWithdrawalNotPermitted),
+		errors.Is(err, ErrNoOutstandingLoanBalance), errors.Is(err, ErrLoanPaymentExceedsBalance),
+		errors.Is(err, ErrAccountNotLoan), errors.Is(err, ErrLoanAlreadyDisbursed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
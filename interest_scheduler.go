@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// interestCheckInterval is how often the background scheduler checks
+// whether any account has interest due; Policy.AccrueInterest decides the
+// actual accrual cadence (e.g. monthly) independently of this.
+const interestCheckInterval = 1 * time.Hour
+
+// InterestScheduler periodically asks every account's Policy whether
+// interest is due and, if so, appends an "interest" transaction and
+// persists the new balance. The accrual interval (e.g. monthly) is a
+// property of the Policy itself; InterestScheduler only controls how often
+// it checks.
+type InterestScheduler struct {
+	storage       Storage
+	checkInterval time.Duration
+}
+
+func NewInterestScheduler(storage Storage, checkInterval time.Duration) *InterestScheduler {
+	return &InterestScheduler{storage: storage, checkInterval: checkInterval}
+}
+
+// Run blocks, ticking every checkInterval until ctx is cancelled. Callers
+// typically start it with `go scheduler.Run(ctx)`.
+func (s *InterestScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.accrueAll(time.Now())
+		}
+	}
+}
+
+func (s *InterestScheduler) accrueAll(now time.Time) {
+	accounts, err := s.storage.GetAllAccounts()
+	if err != nil {
+		fmt.Printf("Ошибка начисления процентов: %v\n", err)
+		return
+	}
+	for _, account := range accounts {
+		if err := s.accrueOne(account, now); err != nil {
+			fmt.Printf("Ошибка начисления процентов по счёту %s: %v\n", account.ID, err)
+		}
+	}
+}
+
+func (s *InterestScheduler) accrueOne(account *Account, now time.Time) error {
+	policy, err := PolicyFor(account.Type)
+	if err != nil {
+		return err
+	}
+	interest, ok := policy.AccrueInterest(account, now)
+	if !ok {
+		return nil
+	}
+	account.Balance += interest.Amount
+	account.History = append(account.History, Transaction{
+		ID:        newTxnID(),
+		Type:      "interest",
+		Amount:    interest.Amount,
+		Currency:  interest.Currency,
+		Timestamp: now,
+	})
+	return s.storage.SaveAccount(account)
+}
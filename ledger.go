@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Posting is one leg of a double-entry move: exactly Amount of Currency
+// moves out of Source and into Destination. ExecuteScript returns the
+// postings a script produced, already applied to the underlying accounts.
+type Posting struct {
+	Source      string
+	Destination string
+	Amount      int64
+	Currency    string
+}
+
+// LedgerStore is how the scripting Machine (see ledger_script.go) resolves
+// account balances and commits the postings a script produces.
+// storageLedgerStore is the only implementation; it adapts the existing
+// Storage interface so scripts move real account funds.
+type LedgerStore interface {
+	Balance(accountID, currency string) (int64, error)
+	// AccountType reports accountID's product type, so the Machine can
+	// resolve its Policy (see account_type.go) and enforce the same
+	// per-product rules a script moves money under as Deposit/Withdraw
+	// and saga transfers do.
+	AccountType(accountID string) (AccountType, error)
+	// ApplyPostings commits every posting or none of them: every touched
+	// account is validated (non-negative balance, unless it's a credit
+	// account) before any of them is persisted.
+	ApplyPostings(postings []Posting) error
+}
+
+type storageLedgerStore struct {
+	storage Storage
+}
+
+func newStorageLedgerStore(storage Storage) *storageLedgerStore {
+	return &storageLedgerStore{storage: storage}
+}
+
+func (s *storageLedgerStore) Balance(accountID, currency string) (int64, error) {
+	account, err := s.storage.LoadAccount(accountID)
+	if err != nil {
+		return 0, err
+	}
+	if account.Currency != currency {
+		return 0, fmt.Errorf("%w: счёт %s в валюте %s, а не %s", ErrCurrencyMismatch, accountID, account.Currency, currency)
+	}
+	return account.Balance, nil
+}
+
+func (s *storageLedgerStore) AccountType(accountID string) (AccountType, error) {
+	account, err := s.storage.LoadAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+	return account.Type, nil
+}
+
+// ApplyPostings stages every posting against the accounts it touches,
+// re-validating each leg against its account's Policy exactly as the
+// Machine did in Run (see ledger_script.go's debit/credit), and only then
+// saves any of them, so a script either lands in full or leaves storage
+// untouched. Re-checking here — against balances freshly loaded from
+// storage rather than trusting what Run validated — is what keeps a
+// concurrent operation on a touched account from landing a posting the
+// Machine only validated against a now-stale balance.
+func (s *storageLedgerStore) ApplyPostings(postings []Posting) error {
+	touched := make(map[string]*Account)
+	policies := make(map[string]Policy)
+	get := func(id string) (*Account, Policy, error) {
+		if acc, ok := touched[id]; ok {
+			return acc, policies[id], nil
+		}
+		acc, err := s.storage.LoadAccount(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		policy, err := PolicyFor(acc.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		touched[id] = acc
+		policies[id] = policy
+		return acc, policy, nil
+	}
+
+	now := time.Now()
+	for _, p := range postings {
+		from, fromPolicy, err := get(p.Source)
+		if err != nil {
+			return err
+		}
+		to, toPolicy, err := get(p.Destination)
+		if err != nil {
+			return err
+		}
+		if from.Currency != p.Currency || to.Currency != p.Currency {
+			return ErrCurrencyMismatch
+		}
+		amount := Money{Amount: p.Amount, Currency: p.Currency}
+		if err := fromPolicy.ValidateWithdraw(from, amount); err != nil {
+			return err
+		}
+		from.Balance -= p.Amount
+		from.History = append(from.History, Transaction{
+			ID: newTxnID(), Type: "ledger_out", Amount: p.Amount, Currency: p.Currency,
+			Timestamp: now, ToFrom: to.ID,
+		})
+		if err := toPolicy.ValidateDeposit(to, amount); err != nil {
+			return err
+		}
+		to.Balance += p.Amount
+		to.History = append(to.History, Transaction{
+			ID: newTxnID(), Type: "ledger_in", Amount: p.Amount, Currency: p.Currency,
+			Timestamp: now, ToFrom: from.ID,
+		})
+	}
+
+	for _, acc := range touched {
+		if err := s.storage.SaveAccount(acc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
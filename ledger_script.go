@@ -0,0 +1,587 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Numscript-style scripting DSL for compound transactions, e.g.:
+//
+//	send [USD 10000] from @alice to @bob
+//	send [USD max 5000] from @alice to @bob
+//	send [USD *] from @alice to @bob
+//	send [USD 10000] from @alice to {
+//	  80% @bob
+//	  20% @charlie
+//	}
+//
+// Amounts are integer minor units, like everywhere else in this app.
+// $name tokens are substituted from the vars map passed to ExecuteScript.
+
+// Opcode identifies one instruction in the stream a Compiler produces.
+type Opcode int
+
+const (
+	OpPush Opcode = iota
+	OpSend
+	OpAllocate
+	OpTakeAll
+	OpTakeMax
+)
+
+// Instruction is one step of the instruction stream the Machine executes.
+// Args is opcode-specific: OpPush carries the single value to push;
+// OpTakeAll carries the currency to resolve a balance in; OpAllocate
+// carries the []Portion to split the popped amount across.
+type Instruction struct {
+	Op   Opcode
+	Args []any
+}
+
+// Portion is one share of an OpAllocate split. Num/Den express the share
+// as a rational number in (0, 1]; Remaining marks the bucket that absorbs
+// whatever is left after every other portion has been taken (so explicit
+// portions need not divide the total evenly).
+type Portion struct {
+	Num, Den  int64
+	Account   string
+	Remaining bool
+}
+
+var tokenPattern = regexp.MustCompile(`@[A-Za-z0-9_]+|\$[A-Za-z0-9_]+|[0-9]+|[A-Za-z_]+|[\[\]{}(),%/*]`)
+
+func tokenize(source string) []string {
+	return tokenPattern.FindAllString(source, -1)
+}
+
+// Compiler turns DSL source into an instruction stream. It is stateless;
+// kept as a type (rather than a bare function) so callers can extend it
+// with options later without changing ExecuteScript's signature.
+type Compiler struct{}
+
+func (Compiler) Compile(source string, vars map[string]any) ([]Instruction, error) {
+	p := &parser{tokens: tokenize(source), vars: vars}
+	var instructions []Instruction
+	for !p.atEnd() {
+		stmt, err := p.parseSend()
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, stmt...)
+	}
+	if len(instructions) == 0 {
+		return nil, fmt.Errorf("скрипт не содержит ни одной инструкции send")
+	}
+	return instructions, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+	vars   map[string]any
+}
+
+func (p *parser) atEnd() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() string {
+	if p.atEnd() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (string, error) {
+	if p.atEnd() {
+		return "", fmt.Errorf("неожиданный конец скрипта")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, nil
+}
+
+func (p *parser) expect(want string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(tok, want) {
+		return fmt.Errorf("ожидалось %q, получено %q", want, tok)
+	}
+	return nil
+}
+
+// resolveNumber resolves a numeric or $var token to an int64.
+func (p *parser) resolveNumber(tok string) (int64, error) {
+	if strings.HasPrefix(tok, "$") {
+		name := tok[1:]
+		v, ok := p.vars[name]
+		if !ok {
+			return 0, fmt.Errorf("переменная %q не определена", name)
+		}
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case int:
+			return int64(n), nil
+		case float64:
+			return int64(n), nil
+		default:
+			return 0, fmt.Errorf("переменная %q должна быть числом", name)
+		}
+	}
+	return strconv.ParseInt(tok, 10, 64)
+}
+
+func accountName(tok string) (string, error) {
+	if !strings.HasPrefix(tok, "@") {
+		return "", fmt.Errorf("ожидался счёт вида @имя, получено %q", tok)
+	}
+	return tok[1:], nil
+}
+
+// parseSend compiles a single `send [...] from @x to ...` statement into
+// its instruction stream.
+func (p *parser) parseSend() ([]Instruction, error) {
+	if err := p.expect("send"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	currencyTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	currency := strings.ToUpper(currencyTok)
+
+	needsSourceForTake := false
+	var amountInstructions []Instruction
+	mode := p.peek()
+	switch {
+	case mode == "*":
+		p.pos++
+		needsSourceForTake = true
+		amountInstructions = append(amountInstructions, Instruction{Op: OpTakeAll, Args: []any{currency}})
+	case strings.EqualFold(mode, "max"):
+		p.pos++
+		amountTok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		amount, err := p.resolveNumber(amountTok)
+		if err != nil {
+			return nil, err
+		}
+		needsSourceForTake = true
+		amountInstructions = append(amountInstructions,
+			Instruction{Op: OpPush, Args: []any{Money{Amount: amount, Currency: currency}}},
+			Instruction{Op: OpTakeMax},
+		)
+	default:
+		amount, err := p.resolveNumber(mode)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		amountInstructions = append(amountInstructions,
+			Instruction{Op: OpPush, Args: []any{Money{Amount: amount, Currency: currency}}},
+		)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	sourceTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	source, err := accountName(sourceTok)
+	if err != nil {
+		return nil, err
+	}
+
+	var instructions []Instruction
+	// OP_TAKE_ALL / OP_TAKE_MAX resolve against the source, so it must be
+	// pushed before them; since they consume it, push it again afterwards
+	// so it's still on the stack for the OP_SEND/OP_ALLOCATE below.
+	if needsSourceForTake {
+		instructions = append(instructions, Instruction{Op: OpPush, Args: []any{ledgerAccount(source)}})
+	}
+	instructions = append(instructions, amountInstructions...)
+	instructions = append(instructions, Instruction{Op: OpPush, Args: []any{ledgerAccount(source)}})
+
+	if err := p.expect("to"); err != nil {
+		return nil, err
+	}
+	if p.peek() == "{" {
+		portions, err := p.parsePortions()
+		if err != nil {
+			return nil, err
+		}
+		if err := validatePortions(portions); err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, Instruction{Op: OpAllocate, Args: []any{portions}})
+		return instructions, nil
+	}
+	destTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	dest, err := accountName(destTok)
+	if err != nil {
+		return nil, err
+	}
+	instructions = append(instructions,
+		Instruction{Op: OpPush, Args: []any{ledgerAccount(dest)}},
+		Instruction{Op: OpSend},
+	)
+	return instructions, nil
+}
+
+func (p *parser) parsePortions() ([]Portion, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var portions []Portion
+	for p.peek() != "}" {
+		if p.peek() == "," {
+			p.pos++
+			continue
+		}
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		var portion Portion
+		if strings.EqualFold(tok, "remaining") {
+			portion.Remaining = true
+		} else {
+			num, err := p.resolveNumber(tok)
+			if err != nil {
+				return nil, err
+			}
+			if p.peek() == "%" {
+				p.pos++
+				portion.Num, portion.Den = num, 100
+			} else if p.peek() == "/" {
+				p.pos++
+				denTok, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				den, err := p.resolveNumber(denTok)
+				if err != nil {
+					return nil, err
+				}
+				portion.Num, portion.Den = num, den
+			} else {
+				return nil, fmt.Errorf("ожидался %% или / после доли %d", num)
+			}
+		}
+		destTok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		dest, err := accountName(destTok)
+		if err != nil {
+			return nil, err
+		}
+		portion.Account = dest
+		portions = append(portions, portion)
+	}
+	p.pos++ // consume "}"
+	return portions, nil
+}
+
+// validatePortions enforces that every explicit share is a rational number
+// in (0, 1], and that shares sum to exactly 1 — the "remaining" bucket, if
+// present, implicitly contributes whatever's left, so explicit shares must
+// then sum to strictly less than 1.
+func validatePortions(portions []Portion) error {
+	if len(portions) == 0 {
+		return fmt.Errorf("список долей не может быть пустым")
+	}
+	sum, hasRemaining := new(big.Rat), false
+	for _, p := range portions {
+		if p.Remaining {
+			hasRemaining = true
+			continue
+		}
+		if p.Den <= 0 || p.Num <= 0 || p.Num > p.Den {
+			return fmt.Errorf("доля должна быть рациональным числом от 0 до 1: %d/%d", p.Num, p.Den)
+		}
+		sum.Add(sum, big.NewRat(p.Num, p.Den))
+	}
+	one := big.NewRat(1, 1)
+	switch {
+	case hasRemaining && sum.Cmp(one) >= 0:
+		return fmt.Errorf("сумма явных долей должна быть меньше 1, если указана доля remaining")
+	case !hasRemaining && sum.Cmp(one) != 0:
+		return fmt.Errorf("сумма долей должна быть равна 1, получено %s", sum.RatString())
+	}
+	return nil
+}
+
+// ledgerAccount distinguishes an account-name stack value from a Money
+// value at runtime; both are carried as `any` on the Machine's stack.
+type ledgerAccount string
+
+// Machine executes a compiled instruction stream against a LedgerStore,
+// resolving balances lazily (only when an instruction needs one:
+// OP_TAKE_ALL/OP_TAKE_MAX to size an amount, OP_SEND/OP_ALLOCATE to check
+// each leg against its account's Policy) and tracking running deltas so later instructions in the same
+// script see the effect of earlier ones before anything is persisted.
+type Machine struct {
+	store    LedgerStore
+	stack    []any
+	deltas   map[string]int64
+	postings []Posting
+}
+
+func NewMachine(store LedgerStore) *Machine {
+	return &Machine{store: store, deltas: make(map[string]int64)}
+}
+
+func (m *Machine) push(v any) { m.stack = append(m.stack, v) }
+
+func (m *Machine) pop() (any, error) {
+	if len(m.stack) == 0 {
+		return nil, fmt.Errorf("пустой стек машины")
+	}
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return v, nil
+}
+
+func (m *Machine) popAccount() (string, error) {
+	v, err := m.pop()
+	if err != nil {
+		return "", err
+	}
+	acc, ok := v.(ledgerAccount)
+	if !ok {
+		return "", fmt.Errorf("ожидался счёт на стеке, получено %T", v)
+	}
+	return string(acc), nil
+}
+
+func (m *Machine) popMoney() (Money, error) {
+	v, err := m.pop()
+	if err != nil {
+		return Money{}, err
+	}
+	money, ok := v.(Money)
+	if !ok {
+		return Money{}, fmt.Errorf("ожидалась сумма на стеке, получено %T", v)
+	}
+	return money, nil
+}
+
+// balanceOf returns accountID's balance in currency as of this execution,
+// i.e. the persisted balance adjusted by every OP_SEND/OP_ALLOCATE already
+// run in this script.
+func (m *Machine) balanceOf(accountID, currency string) (int64, error) {
+	balance, err := m.store.Balance(accountID, currency)
+	if err != nil {
+		return 0, err
+	}
+	return balance + m.deltas[accountID], nil
+}
+
+// policySnapshot resolves accountID's Policy and builds the *Account view
+// of it a Policy expects to validate against: its product type plus its
+// balance as of this point in the script (the persisted balance adjusted
+// by every delta the script has applied so far).
+func (m *Machine) policySnapshot(accountID string, currency string) (Policy, *Account, error) {
+	accountType, err := m.store.AccountType(accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := PolicyFor(accountType)
+	if err != nil {
+		return nil, nil, err
+	}
+	balance, err := m.balanceOf(accountID, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+	return policy, &Account{Type: accountType, Currency: currency, Balance: balance}, nil
+}
+
+// debit validates a movement of amount away from accountID against its
+// Policy (the same one Deposit/Withdraw and saga transfers enforce) and
+// records it, so e.g. a LOAN account can't be drained and a LINE_OF_CREDIT
+// account can't be overdrawn past its limit through a script.
+func (m *Machine) debit(accountID string, amount Money) error {
+	policy, account, err := m.policySnapshot(accountID, amount.Currency)
+	if err != nil {
+		return err
+	}
+	if err := policy.ValidateWithdraw(account, amount); err != nil {
+		return err
+	}
+	m.deltas[accountID] -= amount.Amount
+	return nil
+}
+
+func (m *Machine) credit(accountID string, amount Money) error {
+	policy, account, err := m.policySnapshot(accountID, amount.Currency)
+	if err != nil {
+		return err
+	}
+	if err := policy.ValidateDeposit(account, amount); err != nil {
+		return err
+	}
+	m.deltas[accountID] += amount.Amount
+	return nil
+}
+
+// Run executes instructions and returns the postings it produced. On any
+// error, no postings have been handed to the LedgerStore yet, so nothing
+// needs to be rolled back — the script simply never committed.
+func (m *Machine) Run(instructions []Instruction) ([]Posting, error) {
+	for _, instr := range instructions {
+		switch instr.Op {
+		case OpPush:
+			m.push(instr.Args[0])
+
+		case OpTakeAll:
+			currency := instr.Args[0].(string)
+			account, err := m.popAccount()
+			if err != nil {
+				return nil, err
+			}
+			balance, err := m.balanceOf(account, currency)
+			if err != nil {
+				return nil, err
+			}
+			if balance < 0 {
+				balance = 0
+			}
+			m.push(Money{Amount: balance, Currency: currency})
+
+		case OpTakeMax:
+			maxAmount, err := m.popMoney()
+			if err != nil {
+				return nil, err
+			}
+			account, err := m.popAccount()
+			if err != nil {
+				return nil, err
+			}
+			balance, err := m.balanceOf(account, maxAmount.Currency)
+			if err != nil {
+				return nil, err
+			}
+			if balance < 0 {
+				balance = 0
+			}
+			take := maxAmount.Amount
+			if balance < take {
+				take = balance
+			}
+			m.push(Money{Amount: take, Currency: maxAmount.Currency})
+
+		case OpSend:
+			dest, err := m.popAccount()
+			if err != nil {
+				return nil, err
+			}
+			source, err := m.popAccount()
+			if err != nil {
+				return nil, err
+			}
+			amount, err := m.popMoney()
+			if err != nil {
+				return nil, err
+			}
+			if err := m.debit(source, amount); err != nil {
+				return nil, err
+			}
+			if err := m.credit(dest, amount); err != nil {
+				return nil, err
+			}
+			m.postings = append(m.postings, Posting{Source: source, Destination: dest, Amount: amount.Amount, Currency: amount.Currency})
+
+		case OpAllocate:
+			portions := instr.Args[0].([]Portion)
+			source, err := m.popAccount()
+			if err != nil {
+				return nil, err
+			}
+			amount, err := m.popMoney()
+			if err != nil {
+				return nil, err
+			}
+			shares, err := allocate(amount.Amount, portions)
+			if err != nil {
+				return nil, err
+			}
+			if err := m.debit(source, amount); err != nil {
+				return nil, err
+			}
+			for i, portion := range portions {
+				share := Money{Amount: shares[i], Currency: amount.Currency}
+				if err := m.credit(portion.Account, share); err != nil {
+					return nil, err
+				}
+				m.postings = append(m.postings, Posting{Source: source, Destination: portion.Account, Amount: share.Amount, Currency: amount.Currency})
+			}
+
+		default:
+			return nil, fmt.Errorf("неизвестная инструкция %d", instr.Op)
+		}
+	}
+	return m.postings, nil
+}
+
+// allocate splits total across portions, returning one integer minor-unit
+// share per portion in the same order. Explicit shares are rounded down;
+// the "remaining" portion (or, absent one, the last listed portion)
+// absorbs whatever rounding leaves over, so shares always sum to total
+// exactly.
+func allocate(total int64, portions []Portion) ([]int64, error) {
+	shares := make([]int64, len(portions))
+	var allocated int64
+	remainingIdx := -1
+	for i, p := range portions {
+		if p.Remaining {
+			remainingIdx = i
+			continue
+		}
+		share := total * p.Num / p.Den
+		shares[i] = share
+		allocated += share
+	}
+	leftover := total - allocated
+	if remainingIdx >= 0 {
+		shares[remainingIdx] = leftover
+	} else {
+		shares[len(shares)-1] += leftover
+	}
+	return shares, nil
+}
+
+// ExecuteScript compiles and runs a ledger script against storage,
+// atomically applying the postings it produces.
+func ExecuteScript(storage Storage, source string, vars map[string]any) ([]Posting, error) {
+	instructions, err := (Compiler{}).Compile(source, vars)
+	if err != nil {
+		return nil, err
+	}
+	store := newStorageLedgerStore(storage)
+	postings, err := NewMachine(store).Run(instructions)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.ApplyPostings(postings); err != nil {
+		return nil, err
+	}
+	return postings, nil
+}
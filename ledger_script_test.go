@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestExecuteScriptEnforcesLineOfCreditLimit checks a script can't push a
+// LINE_OF_CREDIT account past its credit limit, the same rule Withdraw
+// enforces outside of scripts.
+func TestExecuteScriptEnforcesLineOfCreditLimit(t *testing.T) {
+	storage := NewInMemoryStorage()
+
+	credit := &Account{Owner: "Alice", Currency: "USD", Type: LineOfCredit, Balance: 0}
+	if err := storage.SaveAccount(credit); err != nil {
+		t.Fatalf("SaveAccount(credit): %v", err)
+	}
+	dest := &Account{Owner: "Bob", Currency: "USD", Type: Checking, Balance: 0}
+	if err := storage.SaveAccount(dest); err != nil {
+		t.Fatalf("SaveAccount(dest): %v", err)
+	}
+
+	source := fmt.Sprintf("send [USD %d] from @%s to @%s", lineOfCreditLimit+100, credit.ID, dest.ID)
+
+	if _, err := ExecuteScript(storage, source, nil); err == nil {
+		t.Fatal("expected ExecuteScript to reject a send that exceeds the credit limit")
+	}
+
+	reloaded, err := storage.LoadAccount(credit.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(credit): %v", err)
+	}
+	if reloaded.Balance != 0 {
+		t.Errorf("credit.Balance = %d, want 0 (rejected postings must not be applied)", reloaded.Balance)
+	}
+}
+
+// TestExecuteScriptRejectsLoanWithdrawal checks a script's legs are
+// validated through Policy (the Machine's debit rejects the send during
+// Run, before ApplyPostings is reached): a script can't drain a LOAN
+// account any more than a direct Withdraw call could.
+func TestExecuteScriptRejectsLoanWithdrawal(t *testing.T) {
+	storage := NewInMemoryStorage()
+
+	loan := &Account{Owner: "Alice", Currency: "USD", Type: Loan, Balance: -50000}
+	if err := storage.SaveAccount(loan); err != nil {
+		t.Fatalf("SaveAccount(loan): %v", err)
+	}
+	dest := &Account{Owner: "Bob", Currency: "USD", Type: Checking, Balance: 0}
+	if err := storage.SaveAccount(dest); err != nil {
+		t.Fatalf("SaveAccount(dest): %v", err)
+	}
+
+	source := "send [USD 1000] from @" + loan.ID + " to @" + dest.ID
+
+	if _, err := ExecuteScript(storage, source, nil); err == nil {
+		t.Fatal("expected ExecuteScript to reject withdrawing from a LOAN account")
+	}
+
+	reloaded, err := storage.LoadAccount(loan.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(loan): %v", err)
+	}
+	if reloaded.Balance != -50000 {
+		t.Errorf("loan.Balance = %d, want -50000 (rejected postings must not be applied)", reloaded.Balance)
+	}
+}
+
+// TestExecuteScriptAppliesValidPostings is the happy path: a send within
+// every touched account's Policy lands on both sides.
+func TestExecuteScriptAppliesValidPostings(t *testing.T) {
+	storage := NewInMemoryStorage()
+
+	from := &Account{Owner: "Alice", Currency: "USD", Type: Checking, Balance: 5000}
+	if err := storage.SaveAccount(from); err != nil {
+		t.Fatalf("SaveAccount(from): %v", err)
+	}
+	to := &Account{Owner: "Bob", Currency: "USD", Type: Checking, Balance: 0}
+	if err := storage.SaveAccount(to); err != nil {
+		t.Fatalf("SaveAccount(to): %v", err)
+	}
+
+	source := "send [USD 2000] from @" + from.ID + " to @" + to.ID
+	if _, err := ExecuteScript(storage, source, nil); err != nil {
+		t.Fatalf("ExecuteScript: %v", err)
+	}
+
+	fromFinal, err := storage.LoadAccount(from.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(from): %v", err)
+	}
+	toFinal, err := storage.LoadAccount(to.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(to): %v", err)
+	}
+	if fromFinal.Balance != 3000 {
+		t.Errorf("from.Balance = %d, want 3000", fromFinal.Balance)
+	}
+	if toFinal.Balance != 2000 {
+		t.Errorf("to.Balance = %d, want 2000", toFinal.Balance)
+	}
+}
@@ -2,10 +2,11 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -15,127 +16,152 @@ var (
 	ErrInvalidAmount       = errors.New("некорректная сумма: должна быть положительной")
 	ErrAccountNotFound     = errors.New("счёт не найден")
 	ErrSameAccountTransfer = errors.New("нельзя перевести деньги на тот же счёт")
+	ErrCurrencyMismatch    = errors.New("валюта операции не совпадает с валютой счёта")
+	ErrNonZeroBalance      = errors.New("нельзя закрыть счёт с ненулевым балансом")
 )
 
 type Transaction struct {
+	ID        string
 	Type      string
-	Amount    float64
+	Amount    int64  // minor units (e.g. kopecks, cents) of Currency
+	Currency  string // ISO 4217
 	Timestamp time.Time
 	ToFrom    string
+	// RateApplied, ConvertedAmount and ConvertedCurrency are set only on
+	// the debit leg of a cross-currency transfer: RateApplied is the
+	// decimal.Decimal rate (as a string, to avoid float drift) used to
+	// compute ConvertedAmount, the amount credited to the destination
+	// account in ConvertedCurrency.
+	RateApplied      string
+	ConvertedAmount  int64
+	ConvertedCurrency string
 }
 
 type Account struct {
-	ID      string
-	Owner   string
-	Balance float64
-	History []Transaction
+	ID       string
+	Owner    string
+	Currency string      // ISO 4217; fixed at OpenAccount time
+	Type     AccountType // fixed at OpenAccount time; selects its Policy
+	Balance  int64       // minor units of Currency
+	History  []Transaction
 }
 
+// AccountService covers single-account mutations. Cross-account transfers
+// are no longer part of this interface: they run through the TransferSaga
+// state machine in saga.go instead of mutating two accounts in place.
 type AccountService interface {
-	Deposit(amount float64) error
-	Withdraw(amount float64) error
-	Transfer(to *Account, amount float64) error
-	GetBalance() float64
+	Deposit(amount Money) error
+	Withdraw(amount Money) error
+	GetBalance() Money
 	GetStatement() string
 }
 
+// Storage is implemented by every persistence driver (in-memory, JSON file,
+// BoltDB, Postgres). Drivers are selected at startup via NewStorage.
 type Storage interface {
 	SaveAccount(account *Account) error
 	LoadAccount(accountID string) (*Account, error)
 	GetAllAccounts() ([]*Account, error)
+	DeleteAccount(accountID string) error
+	ListDeposits(accountID string) ([]Transaction, error)
+	ListWithdrawals(accountID string) ([]Transaction, error)
 }
 
 type AccountServiceImpl struct {
 	account *Account
+	storage Storage
 }
 
-func NewAccountService(account *Account) *AccountServiceImpl {
-	return &AccountServiceImpl{account: account}
+func NewAccountService(account *Account, storage Storage) *AccountServiceImpl {
+	return &AccountServiceImpl{account: account, storage: storage}
 }
 
-func (s *AccountServiceImpl) Deposit(amount float64) error {
-	if amount <= 0 {
-		return ErrInvalidAmount
+func (s *AccountServiceImpl) Deposit(amount Money) error {
+	if amount.Currency != s.account.Currency {
+		return ErrCurrencyMismatch
 	}
-	s.account.Balance += amount
-	s.account.History = append(s.account.History, Transaction{
-		Type:      "deposit",
-		Amount:    amount,
-		Timestamp: time.Now(),
-	})
-	return nil
-}
-
-func (s *AccountServiceImpl) Withdraw(amount float64) error {
-	if amount <= 0 {
+	if amount.Amount <= 0 {
 		return ErrInvalidAmount
 	}
-	if amount > s.account.Balance {
-		return ErrInsufficientFunds
+	policy, err := PolicyFor(s.account.Type)
+	if err != nil {
+		return err
+	}
+	if err := policy.ValidateDeposit(s.account, amount); err != nil {
+		return err
 	}
-	s.account.Balance -= amount
+	s.account.Balance += amount.Amount
 	s.account.History = append(s.account.History, Transaction{
-		Type:      "withdraw",
-		Amount:    amount,
+		ID:        newTxnID(),
+		Type:      "deposit",
+		Amount:    amount.Amount,
+		Currency:  amount.Currency,
 		Timestamp: time.Now(),
 	})
-	return nil
+	return s.storage.SaveAccount(s.account)
 }
 
-func (s *AccountServiceImpl) Transfer(to *Account, amount float64) error {
-	if s.account.ID == to.ID {
-		return ErrSameAccountTransfer
+func (s *AccountServiceImpl) Withdraw(amount Money) error {
+	if amount.Currency != s.account.Currency {
+		return ErrCurrencyMismatch
 	}
-	if amount <= 0 {
+	if amount.Amount <= 0 {
 		return ErrInvalidAmount
 	}
-	if amount > s.account.Balance {
-		return ErrInsufficientFunds
+	policy, err := PolicyFor(s.account.Type)
+	if err != nil {
+		return err
 	}
-
-	s.account.Balance -= amount
-	to.Balance += amount
-
-	now := time.Now()
+	if err := policy.ValidateWithdraw(s.account, amount); err != nil {
+		return err
+	}
+	s.account.Balance -= amount.Amount
 	s.account.History = append(s.account.History, Transaction{
-		Type:      "transfer_out",
-		Amount:    amount,
-		Timestamp: now,
-		ToFrom:    to.ID,
-	})
-	to.History = append(to.History, Transaction{
-		Type:      "transfer_in",
-		Amount:    amount,
-		Timestamp: now,
-		ToFrom:    s.account.ID,
+		ID:        newTxnID(),
+		Type:      "withdraw",
+		Amount:    amount.Amount,
+		Currency:  amount.Currency,
+		Timestamp: time.Now(),
 	})
-
-	return nil
+	return s.storage.SaveAccount(s.account)
 }
 
-func (s *AccountServiceImpl) GetBalance() float64 {
-	return s.account.Balance
+func (s *AccountServiceImpl) GetBalance() Money {
+	return Money{Amount: s.account.Balance, Currency: s.account.Currency}
 }
 
 func (s *AccountServiceImpl) GetStatement() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Выписка по счёту %s (%s):\n", s.account.ID, s.account.Owner))
-	sb.WriteString(fmt.Sprintf("Текущий баланс: %.2f\n", s.account.Balance))
+	sb.WriteString(fmt.Sprintf("Текущий баланс: %s\n", s.GetBalance()))
 	sb.WriteString("История операций:\n")
 	if len(s.account.History) == 0 {
 		sb.WriteString("  (нет операций)\n")
 	} else {
 		for i, tx := range s.account.History {
+			amount := Money{Amount: tx.Amount, Currency: tx.Currency}
 			var desc string
 			switch tx.Type {
 			case "deposit":
-				desc = fmt.Sprintf("Пополнение: +%.2f", tx.Amount)
+				desc = fmt.Sprintf("Пополнение: +%s", amount)
 			case "withdraw":
-				desc = fmt.Sprintf("Снятие: -%.2f", tx.Amount)
+				desc = fmt.Sprintf("Снятие: -%s", amount)
 			case "transfer_out":
-				desc = fmt.Sprintf("Перевод на счёт %s: -%.2f", tx.ToFrom, tx.Amount)
+				desc = fmt.Sprintf("Перевод на счёт %s: -%s", tx.ToFrom, amount)
+				if tx.RateApplied != "" {
+					converted := Money{Amount: tx.ConvertedAmount, Currency: tx.ConvertedCurrency}
+					desc += fmt.Sprintf(" (зачислено %s по курсу %s)", converted, tx.RateApplied)
+				}
 			case "transfer_in":
-				desc = fmt.Sprintf("Перевод со счёта %s: +%.2f", tx.ToFrom, tx.Amount)
+				desc = fmt.Sprintf("Перевод со счёта %s: +%s", tx.ToFrom, amount)
+			case "refund":
+				desc = fmt.Sprintf("Возврат неудавшегося перевода на счёт %s: +%s", tx.ToFrom, amount)
+			case "interest":
+				desc = fmt.Sprintf("Начисление процентов: +%s", amount)
+			case "ledger_out":
+				desc = fmt.Sprintf("Проводка по скрипту на счёт %s: -%s", tx.ToFrom, amount)
+			case "ledger_in":
+				desc = fmt.Sprintf("Проводка по скрипту со счёта %s: +%s", tx.ToFrom, amount)
 			default:
 				desc = fmt.Sprintf("Неизвестная операция (%s)", tx.Type)
 			}
@@ -148,42 +174,6 @@ func (s *AccountServiceImpl) GetStatement() string {
 	return sb.String()
 }
 
-type InMemoryStorage struct {
-	accounts map[string]*Account
-	nextID   int
-}
-
-func NewInMemoryStorage() *InMemoryStorage {
-	return &InMemoryStorage{
-		accounts: make(map[string]*Account),
-		nextID:   1,
-	}
-}
-
-func (s *InMemoryStorage) SaveAccount(account *Account) error {
-	if account.ID == "" {
-		account.ID = fmt.Sprintf("ACC%04d", s.nextID)
-		s.nextID++
-	}
-	s.accounts[account.ID] = account
-	return nil
-}
-
-func (s *InMemoryStorage) LoadAccount(accountID string) (*Account, error) {
-	if acc, ok := s.accounts[accountID]; ok {
-		return acc, nil
-	}
-	return nil, ErrAccountNotFound
-}
-
-func (s *InMemoryStorage) GetAllAccounts() ([]*Account, error) {
-	accounts := make([]*Account, 0, len(s.accounts))
-	for _, acc := range s.accounts {
-		accounts = append(accounts, acc)
-	}
-	return accounts, nil
-}
-
 func readInput(prompt string) string {
 	fmt.Print(prompt)
 	scanner := bufio.NewScanner(os.Stdin)
@@ -191,24 +181,123 @@ func readInput(prompt string) string {
 	return strings.TrimSpace(scanner.Text())
 }
 
-func readFloat(prompt string) (float64, error) {
+// readMoney reads a decimal amount (e.g. "100.50") and parses it into
+// minor units of currency.
+func readMoney(prompt string, currency string) (Money, error) {
 	for {
 		input := readInput(prompt)
 		if input == "" {
-			return 0, errors.New("ввод отменён")
+			return Money{}, errors.New("ввод отменён")
 		}
-		value, err := strconv.ParseFloat(input, 64)
+		amount, err := moneyFromUnits(input, currency)
 		if err != nil {
-			fmt.Println("Некорректное число. Попробуйте ещё раз.")
+			fmt.Println("Некорректная сумма. Попробуйте ещё раз.")
 			continue
 		}
-		return value, nil
+		return amount, nil
+	}
+}
+
+// readScript reads multiple lines of ledger script source until the user
+// enters a blank line.
+func readScript() string {
+	var lines []string
+	for {
+		line := readInput("")
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readAccountType maps the digit chosen at the account-type prompt to an
+// AccountType.
+func readAccountType(choice string) (AccountType, error) {
+	switch choice {
+	case "1":
+		return Checking, nil
+	case "2":
+		return Savings, nil
+	case "3":
+		return MoneyMarket, nil
+	case "4":
+		return LineOfCredit, nil
+	case "5":
+		return Loan, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccountType, choice)
 	}
 }
 
 func main() {
-	storage := NewInMemoryStorage()
+	serve := flag.Bool("serve", false, "запустить gRPC-сервер вместо интерактивного меню")
+	addr := flag.String("addr", defaultGRPCAddr(), "адрес для прослушивания gRPC-сервера")
+	migrateFrom := flag.String("migrate-from", "", "драйвер-источник для разовой миграции (требует -migrate-to); при указании приложение только переносит данные и завершается")
+	migrateTo := flag.String("migrate-to", "", "драйвер-назначение для разовой миграции (требует -migrate-from)")
+	flag.Parse()
+
+	if *migrateFrom != "" || *migrateTo != "" {
+		runMigration(*migrateFrom, *migrateTo)
+		return
+	}
+
+	storage, err := NewStorage(os.Getenv("BANK_STORAGE_DRIVER"))
+	if err != nil {
+		fmt.Printf("Ошибка инициализации хранилища: %v\n", err)
+		os.Exit(1)
+	}
+	svc := NewBankingService(storage, defaultRateProvider())
+
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go NewInterestScheduler(storage, interestCheckInterval).Run(schedulerCtx)
 
+	if *serve {
+		if err := ServeGRPC(*addr, svc); err != nil {
+			fmt.Printf("Ошибка gRPC-сервера: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	runCLI(svc)
+}
+
+// runMigration is the -migrate-from/-migrate-to entry point: it runs
+// MigrateStorage once between two drivers named the same way
+// BANK_STORAGE_DRIVER is, then exits without starting the CLI or gRPC
+// server. json/bolt still read their single path from their usual
+// BANK_JSON_PATH/BANK_BOLT_PATH env vars, so migrating
+// between two stores of the same driver isn't possible this way.
+func runMigration(from, to string) {
+	if from == "" || to == "" {
+		fmt.Println("для миграции нужно указать оба флага: -migrate-from и -migrate-to")
+		os.Exit(1)
+	}
+	src, err := NewStorage(from)
+	if err != nil {
+		fmt.Printf("Ошибка инициализации исходного хранилища: %v\n", err)
+		os.Exit(1)
+	}
+	dst, err := NewStorage(to)
+	if err != nil {
+		fmt.Printf("Ошибка инициализации целевого хранилища: %v\n", err)
+		os.Exit(1)
+	}
+	if err := MigrateStorage(src, dst); err != nil {
+		fmt.Printf("Ошибка миграции: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Миграция из %q в %q завершена успешно\n", from, to)
+}
+
+// runCLI is a thin client over BankingService: it only reads input, prints
+// output, and translates errors to Russian messages. All business logic
+// lives in BankingService so the gRPC server and this loop never drift
+// apart.
+func runCLI(svc *BankingService) {
 	fmt.Println("Добро пожаловать в консольное банковское приложение!")
 	fmt.Println("Для завершения введите 'exit' в любом меню.")
 
@@ -220,6 +309,7 @@ func main() {
 			fmt.Println("1. Создать счёт")
 			fmt.Println("2. Выбрать существующий счёт")
 			fmt.Println("3. Список всех счетов")
+			fmt.Println("4. Выполнить скрипт")
 			fmt.Println("0. Выйти")
 			choice := readInput("Выберите действие: ")
 
@@ -230,12 +320,25 @@ func main() {
 					fmt.Println("Имя не может быть пустым.")
 					continue
 				}
-				account := &Account{
-					Owner:   owner,
-					Balance: 0.0,
-					History: []Transaction{},
+				currency := readInput("Введите валюту счёта (ISO 4217, например RUB): ")
+				if currency == "" {
+					fmt.Println("Валюта не может быть пустой.")
+					continue
 				}
-				err := storage.SaveAccount(account)
+				fmt.Println("Тип счёта: 1) Расчётный 2) Накопительный 3) Денежный рынок 4) Кредитная линия 5) Кредит")
+				accountType, err := readAccountType(readInput("Выберите тип счёта: "))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+					continue
+				}
+				var initialDeposit Money
+				if isDepositProduct(accountType) {
+					initialDeposit, err = readMoney("Введите сумму начального депозита (0, если без депозита): ", currency)
+					if err != nil {
+						continue
+					}
+				}
+				account, err := svc.OpenAccount(owner, currency, accountType, initialDeposit)
 				if err != nil {
 					fmt.Printf("Ошибка создания счёта: %v\n", err)
 					continue
@@ -248,7 +351,7 @@ func main() {
 				if id == "" {
 					continue
 				}
-				acc, err := storage.LoadAccount(id)
+				acc, err := svc.storage.LoadAccount(id)
 				if err != nil {
 					fmt.Printf("%v\n", err)
 					continue
@@ -257,17 +360,31 @@ func main() {
 				fmt.Printf("Вы вошли в счёт %s (%s)\n", acc.ID, acc.Owner)
 
 			case "3":
-				accounts, err := storage.GetAllAccounts()
+				accounts, err := svc.ListAccounts()
 				if err != nil || len(accounts) == 0 {
 					fmt.Println("Нет созданных счетов.")
 				} else {
 					fmt.Println("\n Все счета:")
 					for _, acc := range accounts {
-						fmt.Printf("  %s | %s | Баланс: %.2f\n",
-							acc.ID, acc.Owner, acc.Balance)
+						fmt.Printf("  %s | %s | Баланс: %s\n",
+							acc.ID, acc.Owner, Money{Amount: acc.Balance, Currency: acc.Currency})
 					}
 				}
 
+			case "4":
+				fmt.Println("Введите скрипт (например: send [USD 10000] from @ACC0001 to @ACC0002).")
+				fmt.Println("Завершите ввод пустой строкой.")
+				source := readScript()
+				postings, err := svc.ExecuteScript(source, nil)
+				if err != nil {
+					fmt.Printf("Ошибка выполнения скрипта: %v\n", err)
+					continue
+				}
+				fmt.Printf("Скрипт выполнен, проводок: %d\n", len(postings))
+				for _, p := range postings {
+					fmt.Printf("  %s -> %s: %s\n", p.Source, p.Destination, Money{Amount: p.Amount, Currency: p.Currency})
+				}
+
 			case "0", "exit":
 				fmt.Println("Спасибо за использование! До свидания.")
 				return
@@ -277,7 +394,6 @@ func main() {
 			}
 
 		} else {
-			service := NewAccountService(currentAccount)
 			fmt.Printf("\n=== Счёт %s (%s) ===\n", currentAccount.ID, currentAccount.Owner)
 			fmt.Println("1. Пополнить счёт")
 			fmt.Println("2. Снять средства")
@@ -285,33 +401,37 @@ func main() {
 			fmt.Println("4. Просмотреть баланс")
 			fmt.Println("5. Получить выписку")
 			fmt.Println("6. Выйти из счёта")
+			fmt.Println("7. Закрыть счёт")
+			fmt.Println("8. Выдать кредит (LOAN)")
 			choice := readInput("Выберите действие: ")
 
 			switch choice {
 			case "1":
-				amount, err := readFloat("Введите сумму для пополнения: ")
+				amount, err := readMoney("Введите сумму для пополнения: ", currentAccount.Currency)
 				if err != nil {
 					continue
 				}
-				err = service.Deposit(amount)
+				account, err := svc.Deposit(currentAccount.ID, amount)
 				if err != nil {
 					fmt.Printf("%v\n", err)
 				} else {
-					fmt.Printf("Счёт пополнен на %.2f. Новый баланс: %.2f\n",
-						amount, service.GetBalance())
+					currentAccount = account
+					fmt.Printf("Счёт пополнен на %s. Новый баланс: %s\n",
+						amount, Money{Amount: account.Balance, Currency: account.Currency})
 				}
 
 			case "2":
-				amount, err := readFloat("Введите сумму для снятия: ")
+				amount, err := readMoney("Введите сумму для снятия: ", currentAccount.Currency)
 				if err != nil {
 					continue
 				}
-				err = service.Withdraw(amount)
+				account, err := svc.Withdraw(currentAccount.ID, amount)
 				if err != nil {
 					fmt.Printf("%v\n", err)
 				} else {
-					fmt.Printf("Снято %.2f. Новый баланс: %.2f\n",
-						amount, service.GetBalance())
+					currentAccount = account
+					fmt.Printf("Снято %s. Новый баланс: %s\n",
+						amount, Money{Amount: account.Balance, Currency: account.Currency})
 				}
 
 			case "3":
@@ -319,35 +439,56 @@ func main() {
 				if toID == "" {
 					continue
 				}
-				toAccount, err := storage.LoadAccount(toID)
-				if err != nil {
-					fmt.Printf("%v\n", err)
-					continue
-				}
-				amount, err := readFloat("Введите сумму перевода: ")
+				amount, err := readMoney("Введите сумму перевода: ", currentAccount.Currency)
 				if err != nil {
 					continue
 				}
-				err = service.Transfer(toAccount, amount)
+				account, err := svc.Transfer(currentAccount.ID, toID, amount)
 				if err != nil {
 					fmt.Printf("%v\n", err)
 				} else {
-					_ = storage.SaveAccount(toAccount)
-					_ = storage.SaveAccount(currentAccount)
-					fmt.Printf("Переведено %.2f на счёт %s. Новый баланс: %.2f\n",
-						amount, toID, service.GetBalance())
+					currentAccount = account
+					fmt.Printf("Переведено %s на счёт %s. Новый баланс: %s\n",
+						amount, toID, Money{Amount: account.Balance, Currency: account.Currency})
 				}
 
 			case "4":
-				fmt.Printf("Текущий баланс: %.2f\n", service.GetBalance())
+				fmt.Printf("Текущий баланс: %s\n", Money{Amount: currentAccount.Balance, Currency: currentAccount.Currency})
 
 			case "5":
-				fmt.Println(service.GetStatement())
+				statement, err := svc.GetStatement(currentAccount.ID)
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				} else {
+					fmt.Println(statement)
+				}
 
 			case "6":
 				fmt.Printf("Вы вышли из счёта %s\n", currentAccount.ID)
 				currentAccount = nil
 
+			case "7":
+				if err := svc.CloseAccount(currentAccount.ID); err != nil {
+					fmt.Printf("%v\n", err)
+				} else {
+					fmt.Printf("Счёт %s закрыт\n", currentAccount.ID)
+					currentAccount = nil
+				}
+
+			case "8":
+				amount, err := readMoney("Введите сумму кредита для выдачи: ", currentAccount.Currency)
+				if err != nil {
+					continue
+				}
+				account, err := svc.DisburseLoan(currentAccount.ID, amount)
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				} else {
+					currentAccount = account
+					fmt.Printf("Кредит выдан на %s. Новый баланс: %s\n",
+						amount, Money{Amount: account.Balance, Currency: account.Currency})
+				}
+
 			case "0", "exit":
 				fmt.Println("До свидания!")
 				return
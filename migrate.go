@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+// MigrateStorage copies every account from src into dst, preserving
+// existing IDs. Each account goes through dst.SaveAccount, so dst keeps as
+// much of its history as that driver stores (PostgresStorage keeps only
+// deposit and withdraw entries). It is meant for one-off moves between
+// drivers (e.g. "json" to "postgres") and is not transactional across the
+// two stores: if it fails partway through, re-running it is safe since each
+// account write is idempotent on ID.
+func MigrateStorage(src Storage, dst Storage) error {
+	accounts, err := src.GetAllAccounts()
+	if err != nil {
+		return fmt.Errorf("чтение исходного хранилища: %w", err)
+	}
+	for _, acc := range accounts {
+		if err := dst.SaveAccount(acc); err != nil {
+			return fmt.Errorf("перенос счёта %s: %w", acc.ID, err)
+		}
+	}
+	return nil
+}
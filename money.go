@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Money is an amount of a single currency expressed in integer minor units
+// (e.g. kopecks, cents) so balances never accumulate the float rounding
+// drift that the old float64-based Account.Balance was prone to.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// currencyDecimals holds the number of minor-unit digits for currencies the
+// app has been exercised with; anything absent defaults to 2, which covers
+// the vast majority of ISO 4217 currencies.
+var currencyDecimals = map[string]int{
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"JPY": 0,
+}
+
+func decimalsFor(currency string) int {
+	if d, ok := currencyDecimals[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// String renders the amount with the currency's minor-unit precision, e.g.
+// Money{Amount: 10050, Currency: "USD"}.String() == "100.50 USD".
+func (m Money) String() string {
+	decimals := decimalsFor(m.Currency)
+	if decimals == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	scale := int64(1)
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	whole := amount / scale
+	frac := amount % scale
+	return fmt.Sprintf("%s%d.%0*d %s", sign, whole, decimals, frac, m.Currency)
+}
+
+// moneyFromUnits converts a decimal string amount (as typed by a user, e.g.
+// "100.50") into minor units for the given currency.
+func moneyFromUnits(input string, currency string) (Money, error) {
+	decimals := decimalsFor(currency)
+	whole, frac := splitDecimal(input)
+	scale := int64(1)
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	for len(frac) < decimals {
+		frac += "0"
+	}
+	frac = frac[:decimals]
+
+	// The sign has to come from the raw input, not from wholeUnits: "-0"
+	// parses to the integer 0, which is indistinguishable from a bare "0",
+	// so relying on wholeUnits < 0 silently drops the sign of e.g. "-0.50".
+	negative := strings.HasPrefix(whole, "-")
+
+	var wholeUnits, fracUnits int64
+	if _, err := fmt.Sscanf(whole, "%d", &wholeUnits); err != nil && whole != "" && whole != "-" {
+		return Money{}, fmt.Errorf("некорректная сумма: %s", input)
+	}
+	if decimals > 0 {
+		if _, err := fmt.Sscanf(frac, "%d", &fracUnits); err != nil {
+			return Money{}, fmt.Errorf("некорректная сумма: %s", input)
+		}
+	}
+	if wholeUnits < 0 {
+		wholeUnits = -wholeUnits
+	}
+	amount := wholeUnits*scale + fracUnits
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func splitDecimal(input string) (whole, frac string) {
+	for i, r := range input {
+		if r == '.' || r == ',' {
+			return input[:i], input[i+1:]
+		}
+	}
+	return input, ""
+}
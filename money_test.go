@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestMoneyFromUnitsRoundTrip(t *testing.T) {
+	cases := []struct {
+		input    string
+		currency string
+		want     int64
+	}{
+		{"100.50", "USD", 10050},
+		{"-100.50", "USD", -10050},
+		{"0.50", "USD", 50},
+		{"-0.50", "USD", -50},
+		{"0", "USD", 0},
+		{"-0", "USD", 0},
+		{"5", "JPY", 5},
+		{"-5", "JPY", -5},
+	}
+	for _, c := range cases {
+		got, err := moneyFromUnits(c.input, c.currency)
+		if err != nil {
+			t.Fatalf("moneyFromUnits(%q, %q): unexpected error: %v", c.input, c.currency, err)
+		}
+		if got.Amount != c.want {
+			t.Errorf("moneyFromUnits(%q, %q) = %d, want %d", c.input, c.currency, got.Amount, c.want)
+		}
+		if got.Currency != c.currency {
+			t.Errorf("moneyFromUnits(%q, %q) currency = %q, want %q", c.input, c.currency, got.Currency, c.currency)
+		}
+	}
+}
+
+func TestMoneyStringRoundTrip(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{10050, "USD", "100.50 USD"},
+		{-10050, "USD", "-100.50 USD"},
+		{-50, "USD", "-0.50 USD"},
+		{0, "USD", "0.00 USD"},
+		{5, "JPY", "5 JPY"},
+	}
+	for _, c := range cases {
+		got := Money{Amount: c.amount, Currency: c.currency}.String()
+		if got != c.want {
+			t.Errorf("Money{%d, %q}.String() = %q, want %q", c.amount, c.currency, got, c.want)
+		}
+	}
+}
+
+func TestMoneyFromUnitsInvalid(t *testing.T) {
+	if _, err := moneyFromUnits("abc", "USD"); err == nil {
+		t.Error("moneyFromUnits(\"abc\", \"USD\") should have failed")
+	}
+}
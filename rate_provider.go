@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultRateProvider picks an HTTP-backed provider when BANK_RATE_API_URL
+// is set, otherwise falls back to a small static table covering the
+// currencies this app ships with.
+func defaultRateProvider() RateProvider {
+	if url := os.Getenv("BANK_RATE_API_URL"); url != "" {
+		return NewHTTPRateProvider(url)
+	}
+	return StaticRateProvider{
+		"USD": {"RUB": decimal.NewFromInt(90), "EUR": decimal.NewFromFloat(0.92)},
+		"EUR": {"RUB": decimal.NewFromInt(98), "USD": decimal.NewFromFloat(1.09)},
+		"RUB": {"USD": decimal.NewFromFloat(0.011), "EUR": decimal.NewFromFloat(0.0102)},
+	}
+}
+
+// RateProvider resolves the exchange rate to multiply an amount in `from`
+// by to get the equivalent amount in `to`.
+type RateProvider interface {
+	Rate(from, to string) (decimal.Decimal, error)
+}
+
+// StaticRateProvider is a fixed from->to->rate table, mainly for tests and
+// for deployments that don't need live rates.
+type StaticRateProvider map[string]map[string]decimal.Decimal
+
+func (p StaticRateProvider) Rate(from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if byFrom, ok := p[from]; ok {
+		if rate, ok := byFrom[to]; ok {
+			return rate, nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("нет курса обмена %s -> %s", from, to)
+}
+
+// HTTPRateProvider fetches live rates from an exchange-rate HTTP API, e.g.
+// https://api.exchangerate.host/latest?base=USD&symbols=EUR.
+type HTTPRateProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewHTTPRateProvider(baseURL string) *HTTPRateProvider {
+	return &HTTPRateProvider{
+		BaseURL: baseURL,
+		Client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type httpRateResponse struct {
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+func (p *HTTPRateProvider) Rate(from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	url := fmt.Sprintf("%s/latest?base=%s&symbols=%s", p.BaseURL, from, to)
+	resp, err := p.Client.Get(url)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("запрос курса обмена: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decimal.Zero, fmt.Errorf("сервис курсов вернул статус %d", resp.StatusCode)
+	}
+
+	var parsed httpRateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return decimal.Zero, fmt.Errorf("разбор ответа сервиса курсов: %w", err)
+	}
+	rate, ok := parsed.Rates[to]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("сервис курсов не вернул курс для %s", to)
+	}
+	return rate, nil
+}
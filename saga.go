@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SagaStatus is a state in the TransferSaga state machine.
+type SagaStatus string
+
+const (
+	SagaStarted     SagaStatus = "Started"
+	SagaWithdrawing SagaStatus = "Withdrawing"
+	SagaDepositing  SagaStatus = "Depositing"
+	SagaSucceeded   SagaStatus = "Succeeded"
+	SagaFailed      SagaStatus = "Failed"
+	SagaRefunding   SagaStatus = "Refunding"
+)
+
+func (s SagaStatus) terminal() bool {
+	return s == SagaSucceeded || s == SagaFailed
+}
+
+// TransferSaga is the durable record of a single cross-account transfer.
+// It is keyed by ReferenceID, which also doubles as the idempotency key for
+// every leg the saga performs, so replaying a step after a crash never
+// double-charges an account.
+type TransferSaga struct {
+	ReferenceID string
+	FromID      string
+	ToID        string
+	// Amount is debited from FromID in its own currency; ConvertedAmount
+	// is credited to ToID in its own currency. They're equal (and
+	// RateApplied is 1) when both accounts share a currency. The rate is
+	// resolved once, at TransferAsync time, and stored here so replaying a
+	// step after a crash reapplies the exact same conversion rather than
+	// re-pricing against a rate that may have since moved.
+	Amount          Money
+	ConvertedAmount Money
+	RateApplied     decimal.Decimal
+	Status          SagaStatus
+	Attempts        int
+	LastError       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ErrStorageConflict is returned by SagaStore.CompareAndSwap when the
+// stored saga no longer matches the expected state, i.e. another runner is
+// (or already has) processed this step.
+var ErrStorageConflict = errors.New("конфликт версий состояния саги")
+
+// SagaStore persists TransferSaga state with optimistic concurrency
+// control, so two runners racing to resume the same saga can't both apply
+// the same step.
+type SagaStore interface {
+	Load(ctx context.Context, referenceID string) (*TransferSaga, error)
+	CompareAndSwap(ctx context.Context, referenceID string, newState *TransferSaga, expected *TransferSaga) error
+	// ListNonTerminal returns every saga not yet in a terminal status, for
+	// the startup recovery worker to resume.
+	ListNonTerminal(ctx context.Context) ([]*TransferSaga, error)
+}
+
+// InMemorySagaStore guards sagas with mu: TransferAsync spawns a goroutine
+// per transfer, so Load/CompareAndSwap/ListNonTerminal run concurrently
+// across every in-flight saga.
+type InMemorySagaStore struct {
+	mu    sync.Mutex
+	sagas map[string]*TransferSaga
+}
+
+func NewInMemorySagaStore() *InMemorySagaStore {
+	return &InMemorySagaStore{sagas: make(map[string]*TransferSaga)}
+}
+
+func (s *InMemorySagaStore) Load(ctx context.Context, referenceID string) (*TransferSaga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga, ok := s.sagas[referenceID]
+	if !ok {
+		return nil, fmt.Errorf("сага %s: %w", referenceID, ErrAccountNotFound)
+	}
+	dup := *saga
+	return &dup, nil
+}
+
+func (s *InMemorySagaStore) CompareAndSwap(ctx context.Context, referenceID string, newState *TransferSaga, expected *TransferSaga) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.sagas[referenceID]
+	if expected == nil {
+		if ok {
+			return ErrStorageConflict
+		}
+	} else {
+		if !ok || current.Status != expected.Status {
+			return ErrStorageConflict
+		}
+	}
+	stored := *newState
+	stored.UpdatedAt = time.Now()
+	s.sagas[referenceID] = &stored
+	return nil
+}
+
+func (s *InMemorySagaStore) ListNonTerminal(ctx context.Context) ([]*TransferSaga, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*TransferSaga
+	for _, saga := range s.sagas {
+		if !saga.Status.terminal() {
+			dup := *saga
+			out = append(out, &dup)
+		}
+	}
+	return out, nil
+}
+
+// isTerminalTransferError reports whether err is a business rule violation
+// that retrying can never fix, as opposed to a transient infrastructure
+// error (e.g. a storage timeout) worth retrying with backoff.
+func isTerminalTransferError(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrInvalidAmount) ||
+		errors.Is(err, ErrAccountNotFound) ||
+		errors.Is(err, ErrSameAccountTransfer) ||
+		errors.Is(err, ErrUnknownAccountType) ||
+		errors.Is(err, ErrBelowMinimumBalance) ||
+		errors.Is(err, ErrCreditLimitExceeded) ||
+		errors.Is(err, ErrLoanWithdrawalNotPermitted) ||
+		errors.Is(err, ErrNoOutstandingLoanBalance) ||
+		errors.Is(err, ErrLoanPaymentExceedsBalance)
+}
+
+// legPosting carries the details of a single leg beyond the bare amount,
+// for transactions that need to show the FX conversion applied (only the
+// transfer_out leg of a cross-currency transfer sets Rate/Converted).
+type legPosting struct {
+	legEffect    string // "withdraw" or "deposit"
+	label        string // Transaction.Type
+	amount       Money
+	counterparty string
+	rate         decimal.Decimal
+	converted    Money
+}
+
+// applyLeg posts a single debit/credit leg idempotently: if a transaction
+// with txnID already exists on the account, the leg is assumed already
+// applied and is a no-op.
+func applyLeg(storage Storage, accountID, txnID string, p legPosting) (account *Account, applied bool, err error) {
+	account, err = storage.LoadAccount(accountID)
+	if err != nil {
+		return nil, false, err
+	}
+	if account.Currency != p.amount.Currency {
+		return nil, false, fmt.Errorf("счёт %s в валюте %s, а операция саги в %s", accountID, account.Currency, p.amount.Currency)
+	}
+	for _, tx := range account.History {
+		if tx.ID == txnID {
+			return account, false, nil
+		}
+	}
+
+	policy, err := PolicyFor(account.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	switch p.legEffect {
+	case "withdraw":
+		if err := policy.ValidateWithdraw(account, p.amount); err != nil {
+			return nil, false, err
+		}
+		account.Balance -= p.amount.Amount
+	case "deposit":
+		if err := policy.ValidateDeposit(account, p.amount); err != nil {
+			return nil, false, err
+		}
+		account.Balance += p.amount.Amount
+	default:
+		return nil, false, fmt.Errorf("неизвестный тип операции саги: %s", p.legEffect)
+	}
+
+	txn := Transaction{
+		ID:        txnID,
+		Type:      p.label,
+		Amount:    p.amount.Amount,
+		Currency:  p.amount.Currency,
+		Timestamp: time.Now(),
+		ToFrom:    p.counterparty,
+	}
+	if !p.rate.IsZero() {
+		txn.RateApplied = p.rate.String()
+		txn.ConvertedAmount = p.converted.Amount
+		txn.ConvertedCurrency = p.converted.Currency
+	}
+	account.History = append(account.History, txn)
+	if err := storage.SaveAccount(account); err != nil {
+		return nil, false, err
+	}
+	return account, true, nil
+}
+
+// TransferRunner executes TransferSagas against a SagaStore, retrying
+// transient failures with exponential backoff and compensating (refunding)
+// the source account if the deposit leg can never succeed.
+type TransferRunner struct {
+	storage     Storage
+	sagaStore   SagaStore
+	rates       RateProvider
+	maxRetries  int
+	baseBackoff time.Duration
+	// onLeg, if set, is notified after each leg is newly applied (not on
+	// idempotent replays), so callers can fan the posting out to
+	// WatchTransactions subscribers.
+	onLeg func(accountID string, txn Transaction)
+}
+
+func NewTransferRunner(storage Storage, sagaStore SagaStore, rates RateProvider) *TransferRunner {
+	return &TransferRunner{
+		storage:     storage,
+		sagaStore:   sagaStore,
+		rates:       rates,
+		maxRetries:  5,
+		baseBackoff: 100 * time.Millisecond,
+	}
+}
+
+// TransferAsync starts a new saga and returns immediately with its
+// reference ID; the transfer itself runs in the background. Use
+// WaitForTransfer to block until it reaches a terminal state. The exchange
+// rate (if the two accounts don't share a currency) is resolved once, here,
+// and frozen into the saga so a crash-and-retry never re-prices the
+// transfer against a rate that has since moved.
+func (r *TransferRunner) TransferAsync(fromID, toID string, amount Money) (string, error) {
+	if fromID == toID {
+		return "", ErrSameAccountTransfer
+	}
+	if amount.Amount <= 0 {
+		return "", ErrInvalidAmount
+	}
+
+	from, err := r.storage.LoadAccount(fromID)
+	if err != nil {
+		return "", err
+	}
+	if from.Currency != amount.Currency {
+		return "", ErrCurrencyMismatch
+	}
+	to, err := r.storage.LoadAccount(toID)
+	if err != nil {
+		return "", err
+	}
+
+	rate := decimal.NewFromInt(1)
+	converted := amount
+	if to.Currency != from.Currency {
+		rate, err = r.rates.Rate(from.Currency, to.Currency)
+		if err != nil {
+			return "", err
+		}
+		convertedAmount := decimal.NewFromInt(amount.Amount).Mul(rate).Round(0).IntPart()
+		converted = Money{Amount: convertedAmount, Currency: to.Currency}
+	}
+
+	referenceID := newTxnID()
+	saga := &TransferSaga{
+		ReferenceID:     referenceID,
+		FromID:          fromID,
+		ToID:            toID,
+		Amount:          amount,
+		ConvertedAmount: converted,
+		RateApplied:     rate,
+		Status:          SagaStarted,
+		CreatedAt:       time.Now(),
+	}
+	if err := r.sagaStore.CompareAndSwap(context.Background(), referenceID, saga, nil); err != nil {
+		return "", err
+	}
+
+	go r.run(context.Background(), referenceID)
+	return referenceID, nil
+}
+
+// WaitForTransfer polls the saga until it reaches Succeeded or Failed, or
+// ctx is cancelled.
+func (r *TransferRunner) WaitForTransfer(ctx context.Context, referenceID string) (*TransferSaga, error) {
+	for {
+		saga, err := r.sagaStore.Load(ctx, referenceID)
+		if err != nil {
+			return nil, err
+		}
+		if saga.Status.terminal() {
+			return saga, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// RecoverSagas resumes every non-terminal saga found in the store; it is
+// meant to be called once at startup so a crash mid-transfer is picked
+// back up rather than left stuck.
+func (r *TransferRunner) RecoverSagas(ctx context.Context) error {
+	sagas, err := r.sagaStore.ListNonTerminal(ctx)
+	if err != nil {
+		return err
+	}
+	for _, saga := range sagas {
+		go r.run(ctx, saga.ReferenceID)
+	}
+	return nil
+}
+
+func (r *TransferRunner) run(ctx context.Context, referenceID string) {
+	for {
+		saga, err := r.sagaStore.Load(ctx, referenceID)
+		if err != nil {
+			return
+		}
+		if saga.Status.terminal() {
+			return
+		}
+		if !r.step(ctx, saga) {
+			return
+		}
+	}
+}
+
+// step executes one state transition and reports whether the caller should
+// keep looping (true) or stop because the saga is terminal or the store
+// conflicted with a concurrent runner (false).
+func (r *TransferRunner) step(ctx context.Context, saga *TransferSaga) bool {
+	switch saga.Status {
+	case SagaStarted:
+		return r.transition(ctx, saga, SagaWithdrawing, nil)
+
+	case SagaWithdrawing:
+		withdrawLeg := legPosting{
+			legEffect: "withdraw", label: "transfer_out",
+			amount: saga.Amount, counterparty: saga.ToID,
+		}
+		if saga.ConvertedAmount.Currency != saga.Amount.Currency {
+			withdrawLeg.rate, withdrawLeg.converted = saga.RateApplied, saga.ConvertedAmount
+		}
+		account, applied, err := applyLeg(r.storage, saga.FromID, saga.ReferenceID+":withdraw", withdrawLeg)
+		if err == nil {
+			r.notify(account, applied)
+			return r.transition(ctx, saga, SagaDepositing, nil)
+		}
+		if isTerminalTransferError(err) {
+			return r.transition(ctx, saga, SagaFailed, err)
+		}
+		return r.retry(ctx, saga, err)
+
+	case SagaDepositing:
+		account, applied, err := applyLeg(r.storage, saga.ToID, saga.ReferenceID+":deposit", legPosting{
+			legEffect: "deposit", label: "transfer_in",
+			amount: saga.ConvertedAmount, counterparty: saga.FromID,
+		})
+		if err == nil {
+			r.notify(account, applied)
+			return r.transition(ctx, saga, SagaSucceeded, nil)
+		}
+		if isTerminalTransferError(err) {
+			return r.transition(ctx, saga, SagaRefunding, err)
+		}
+		return r.retry(ctx, saga, err)
+
+	case SagaRefunding:
+		account, applied, err := applyLeg(r.storage, saga.FromID, saga.ReferenceID+":refund", legPosting{
+			legEffect: "deposit", label: "refund",
+			amount: saga.Amount, counterparty: saga.ToID,
+		})
+		if err == nil {
+			r.notify(account, applied)
+			return r.transition(ctx, saga, SagaFailed, nil)
+		}
+		return r.retry(ctx, saga, err)
+
+	default:
+		return false
+	}
+}
+
+func (r *TransferRunner) notify(account *Account, applied bool) {
+	if !applied || r.onLeg == nil || len(account.History) == 0 {
+		return
+	}
+	r.onLeg(account.ID, account.History[len(account.History)-1])
+}
+
+func (r *TransferRunner) transition(ctx context.Context, saga *TransferSaga, next SagaStatus, causeErr error) bool {
+	newState := *saga
+	newState.Status = next
+	if causeErr != nil {
+		newState.LastError = causeErr.Error()
+	}
+	err := r.sagaStore.CompareAndSwap(ctx, saga.ReferenceID, &newState, saga)
+	return err == nil
+}
+
+func (r *TransferRunner) retry(ctx context.Context, saga *TransferSaga, err error) bool {
+	if saga.Attempts >= r.maxRetries {
+		return r.transition(ctx, saga, SagaFailed, err)
+	}
+	newState := *saga
+	newState.Attempts++
+	newState.LastError = err.Error()
+	if casErr := r.sagaStore.CompareAndSwap(ctx, saga.ReferenceID, &newState, saga); casErr != nil {
+		return false
+	}
+	backoff := r.baseBackoff * time.Duration(1<<uint(newState.Attempts))
+	jitter := time.Duration(rand.Int63n(int64(r.baseBackoff)))
+	time.Sleep(backoff + jitter)
+	return true
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestInMemorySagaStoreConcurrentAccess exercises Load/CompareAndSwap/
+// ListNonTerminal from many goroutines at once, exactly the pattern
+// TransferAsync produces (one goroutine per in-flight transfer); run with
+// -race, this is what used to trip a concurrent map read/write.
+func TestInMemorySagaStoreConcurrentAccess(t *testing.T) {
+	store := NewInMemorySagaStore()
+	ctx := context.Background()
+
+	const sagas = 10
+	var wg sync.WaitGroup
+	for i := 0; i < sagas; i++ {
+		referenceID := newTxnID()
+		if err := store.CompareAndSwap(ctx, referenceID, &TransferSaga{ReferenceID: referenceID, Status: SagaStarted}, nil); err != nil {
+			t.Fatalf("seed CompareAndSwap: %v", err)
+		}
+
+		wg.Add(1)
+		go func(referenceID string) {
+			defer wg.Done()
+			for {
+				saga, err := store.Load(ctx, referenceID)
+				if err != nil {
+					t.Errorf("Load: %v", err)
+					return
+				}
+				if saga.Status.terminal() {
+					return
+				}
+				next := *saga
+				next.Status = SagaSucceeded
+				if err := store.CompareAndSwap(ctx, referenceID, &next, saga); err != nil {
+					continue
+				}
+			}
+		}(referenceID)
+
+		go func() {
+			if _, err := store.ListNonTerminal(ctx); err != nil {
+				t.Errorf("ListNonTerminal: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestTransferSucceeds checks the saga's happy path: both legs land and
+// the saga ends Succeeded.
+func TestTransferSucceeds(t *testing.T) {
+	storage := NewInMemoryStorage()
+	svc := NewBankingService(storage, StaticRateProvider{})
+
+	from, err := svc.OpenAccount("Alice", "USD", Checking, Money{Amount: 10000, Currency: "USD"})
+	if err != nil {
+		t.Fatalf("OpenAccount(from): %v", err)
+	}
+	to, err := svc.OpenAccount("Bob", "USD", Checking, Money{})
+	if err != nil {
+		t.Fatalf("OpenAccount(to): %v", err)
+	}
+
+	if _, err := svc.Transfer(from.ID, to.ID, Money{Amount: 4000, Currency: "USD"}); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+
+	fromFinal, err := storage.LoadAccount(from.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(from): %v", err)
+	}
+	toFinal, err := storage.LoadAccount(to.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(to): %v", err)
+	}
+	if fromFinal.Balance != 6000 {
+		t.Errorf("from.Balance = %d, want 6000", fromFinal.Balance)
+	}
+	if toFinal.Balance != 4000 {
+		t.Errorf("to.Balance = %d, want 4000", toFinal.Balance)
+	}
+}
+
+// TestTransferCompensatesWhenDestinationClosedMidFlight simulates a crash
+// between the withdraw and deposit legs (the destination account
+// disappears after the saga has already committed to withdrawing from the
+// source) and checks the saga refunds the source rather than leaving it
+// permanently short.
+func TestTransferCompensatesWhenDestinationClosedMidFlight(t *testing.T) {
+	storage := NewInMemoryStorage()
+	sagaStore := NewInMemorySagaStore()
+	runner := NewTransferRunner(storage, sagaStore, StaticRateProvider{})
+
+	from := &Account{Owner: "Alice", Currency: "USD", Type: Checking, Balance: 10000}
+	if err := storage.SaveAccount(from); err != nil {
+		t.Fatalf("SaveAccount(from): %v", err)
+	}
+	to := &Account{Owner: "Bob", Currency: "USD", Type: Checking, Balance: 0}
+	if err := storage.SaveAccount(to); err != nil {
+		t.Fatalf("SaveAccount(to): %v", err)
+	}
+
+	referenceID, err := runner.TransferAsync(from.ID, to.ID, Money{Amount: 2500, Currency: "USD"})
+	if err != nil {
+		t.Fatalf("TransferAsync: %v", err)
+	}
+
+	// Race the destination account out from under the in-flight saga, as
+	// if a concurrent CloseAccount happened between the withdraw and
+	// deposit legs.
+	_ = storage.DeleteAccount(to.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	saga, err := runner.WaitForTransfer(ctx, referenceID)
+	if err != nil {
+		t.Fatalf("WaitForTransfer: %v", err)
+	}
+	if saga.Status != SagaFailed {
+		t.Fatalf("saga.Status = %s, want %s", saga.Status, SagaFailed)
+	}
+
+	fromFinal, err := storage.LoadAccount(from.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount(from): %v", err)
+	}
+	if fromFinal.Balance != 10000 {
+		t.Errorf("from.Balance = %d, want 10000 (refund after failed deposit leg)", fromFinal.Balance)
+	}
+
+	var refunded bool
+	for _, tx := range fromFinal.History {
+		if tx.Type == "refund" {
+			refunded = true
+		}
+	}
+	if !refunded {
+		t.Error("expected a refund transaction on the source account's history")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// NewStorage selects a Storage driver by name. Supported values are
+// "memory" (default), "json", "bolt" and "postgres"; the driver is
+// typically chosen via the BANK_STORAGE_DRIVER environment variable.
+func NewStorage(driver string) (Storage, error) {
+	switch driver {
+	case "", "memory":
+		return NewInMemoryStorage(), nil
+	case "json":
+		return NewJSONFileStorage(jsonStoragePath())
+	case "bolt":
+		return NewBoltStorage(boltStoragePath())
+	case "postgres":
+		return NewPostgresStorage(postgresDSN())
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер хранилища: %s", driver)
+	}
+}
+
+// newTxnID generates an identifier used as the txn_id column by the SQL
+// drivers and as Transaction.ID everywhere else.
+func newTxnID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return "TXN" + hex.EncodeToString(buf)
+}
+
+// InMemoryStorage guards every access with mu, the same single-mutex
+// granularity JSONFileStorage uses, so concurrent gRPC calls never race on
+// the accounts map.
+type InMemoryStorage struct {
+	mu       sync.Mutex
+	accounts map[string]*Account
+	nextID   int
+}
+
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		accounts: make(map[string]*Account),
+		nextID:   1,
+	}
+}
+
+// cloneAccount returns a defensive copy of account, independent down to its
+// History slice. JSONFileStorage/BoltStorage get this for free every call
+// since they round-trip through (de)serialization; InMemoryStorage holds
+// live pointers in its map, so it has to copy explicitly on every way an
+// *Account crosses its boundary, or a caller mutating what it got back
+// would be mutating InMemoryStorage's own state out from under it.
+func cloneAccount(account *Account) *Account {
+	clone := *account
+	clone.History = append([]Transaction(nil), account.History...)
+	return &clone
+}
+
+func (s *InMemoryStorage) SaveAccount(account *Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account.ID == "" {
+		account.ID = fmt.Sprintf("ACC%04d", s.nextID)
+		s.nextID++
+	}
+	s.accounts[account.ID] = cloneAccount(account)
+	return nil
+}
+
+func (s *InMemoryStorage) LoadAccount(accountID string) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if acc, ok := s.accounts[accountID]; ok {
+		return cloneAccount(acc), nil
+	}
+	return nil, ErrAccountNotFound
+}
+
+func (s *InMemoryStorage) GetAllAccounts() ([]*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := make([]*Account, 0, len(s.accounts))
+	for _, acc := range s.accounts {
+		accounts = append(accounts, cloneAccount(acc))
+	}
+	return accounts, nil
+}
+
+func (s *InMemoryStorage) DeleteAccount(accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[accountID]; !ok {
+		return ErrAccountNotFound
+	}
+	delete(s.accounts, accountID)
+	return nil
+}
+
+func (s *InMemoryStorage) ListDeposits(accountID string) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filterHistory(s.accounts, accountID, "deposit")
+}
+
+func (s *InMemoryStorage) ListWithdrawals(accountID string) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return filterHistory(s.accounts, accountID, "withdraw")
+}
+
+func filterHistory(accounts map[string]*Account, accountID, txnType string) ([]Transaction, error) {
+	acc, ok := accounts[accountID]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	var out []Transaction
+	for _, tx := range acc.History {
+		if tx.Type == txnType {
+			out = append(out, tx)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltBucketAccounts = []byte("accounts")
+)
+
+// BoltStorage persists accounts in a single-file embedded BoltDB database,
+// giving the JSON driver's simplicity but with real ACID transactions
+// instead of a whole-file rewrite on every save.
+type BoltStorage struct {
+	db *bolt.DB
+}
+
+func boltStoragePath() string {
+	if p := os.Getenv("BANK_BOLT_PATH"); p != "" {
+		return p
+	}
+	return "accounts.db"
+}
+
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("открытие bolt-хранилища %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucketAccounts)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStorage{db: db}, nil
+}
+
+func (s *BoltStorage) nextAccountID(tx *bolt.Tx) string {
+	b := tx.Bucket(boltBucketAccounts)
+	n, _ := b.NextSequence()
+	return fmt.Sprintf("ACC%04d", n)
+}
+
+func (s *BoltStorage) SaveAccount(account *Account) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucketAccounts)
+		if account.ID == "" {
+			account.ID = s.nextAccountID(tx)
+		}
+		data, err := json.Marshal(account)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(account.ID), data)
+	})
+}
+
+func (s *BoltStorage) LoadAccount(accountID string) (*Account, error) {
+	var account Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucketAccounts)
+		data := b.Get([]byte(accountID))
+		if data == nil {
+			return ErrAccountNotFound
+		}
+		return json.Unmarshal(data, &account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (s *BoltStorage) GetAllAccounts() ([]*Account, error) {
+	var accounts []*Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucketAccounts)
+		return b.ForEach(func(k, v []byte) error {
+			var account Account
+			if err := json.Unmarshal(v, &account); err != nil {
+				return err
+			}
+			accounts = append(accounts, &account)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+func (s *BoltStorage) DeleteAccount(accountID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucketAccounts)
+		if b.Get([]byte(accountID)) == nil {
+			return ErrAccountNotFound
+		}
+		return b.Delete([]byte(accountID))
+	})
+}
+
+func (s *BoltStorage) ListDeposits(accountID string) ([]Transaction, error) {
+	acc, err := s.LoadAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	accounts := map[string]*Account{accountID: acc}
+	return filterHistory(accounts, accountID, "deposit")
+}
+
+func (s *BoltStorage) ListWithdrawals(accountID string) ([]Transaction, error) {
+	acc, err := s.LoadAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	accounts := map[string]*Account{accountID: acc}
+	return filterHistory(accounts, accountID, "withdraw")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONFileStorage persists all accounts as a single JSON document on disk.
+// Writes are atomic: the new document is written to a temp file in the same
+// directory and then renamed over the target, so a crash mid-write can
+// never leave a truncated or partially-written file behind.
+type JSONFileStorage struct {
+	mu     sync.Mutex
+	path   string
+	nextID int
+}
+
+func jsonStoragePath() string {
+	if p := os.Getenv("BANK_JSON_PATH"); p != "" {
+		return p
+	}
+	return "accounts.json"
+}
+
+func NewJSONFileStorage(path string) (*JSONFileStorage, error) {
+	s := &JSONFileStorage{path: path, nextID: 1}
+	accounts, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	for id := range accounts {
+		var n int
+		if _, err := fmt.Sscanf(id, "ACC%04d", &n); err == nil && n >= s.nextID {
+			s.nextID = n + 1
+		}
+	}
+	return s, nil
+}
+
+func (s *JSONFileStorage) readAll() (map[string]*Account, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return make(map[string]*Account), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[string]*Account)
+	if len(data) == 0 {
+		return accounts, nil
+	}
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("чтение хранилища %s: %w", s.path, err)
+	}
+	return accounts, nil
+}
+
+// writeAll serializes accounts and atomically replaces the storage file.
+// Caller must hold s.mu.
+func (s *JSONFileStorage) writeAll(accounts map[string]*Account) error {
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, ".accounts-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+func (s *JSONFileStorage) SaveAccount(account *Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if account.ID == "" {
+		account.ID = fmt.Sprintf("ACC%04d", s.nextID)
+		s.nextID++
+	}
+	accounts[account.ID] = account
+	return s.writeAll(accounts)
+}
+
+func (s *JSONFileStorage) LoadAccount(accountID string) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	acc, ok := accounts[accountID]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return acc, nil
+}
+
+func (s *JSONFileStorage) GetAllAccounts() ([]*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Account, 0, len(accounts))
+	for _, acc := range accounts {
+		out = append(out, acc)
+	}
+	return out, nil
+}
+
+func (s *JSONFileStorage) DeleteAccount(accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if _, ok := accounts[accountID]; !ok {
+		return ErrAccountNotFound
+	}
+	delete(accounts, accountID)
+	return s.writeAll(accounts)
+}
+
+func (s *JSONFileStorage) ListDeposits(accountID string) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	return filterHistory(accounts, accountID, "deposit")
+}
+
+func (s *JSONFileStorage) ListWithdrawals(accountID string) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	return filterHistory(accounts, accountID, "withdraw")
+}
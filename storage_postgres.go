@@ -0,0 +1,238 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresSchema creates the accounts table plus the deposits/withdraws
+// ledger tables, mirroring the gid/txn_id/amount/asset/timestamp/
+// counterparty layout used by exchange-sync tooling elsewhere.
+const postgresSchema = `
+CREATE SEQUENCE IF NOT EXISTS accounts_id_seq;
+
+CREATE TABLE IF NOT EXISTS accounts (
+	id           TEXT PRIMARY KEY,
+	owner        TEXT NOT NULL,
+	currency     TEXT NOT NULL DEFAULT 'RUB',
+	account_type TEXT NOT NULL DEFAULT 'CHECKING',
+	balance      BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS deposits (
+	gid         BIGSERIAL PRIMARY KEY,
+	account_id  TEXT NOT NULL REFERENCES accounts(id),
+	txn_id      TEXT NOT NULL UNIQUE,
+	amount      BIGINT NOT NULL,
+	asset       TEXT NOT NULL DEFAULT 'RUB',
+	timestamp   TIMESTAMPTZ NOT NULL,
+	counterparty TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS withdraws (
+	gid         BIGSERIAL PRIMARY KEY,
+	account_id  TEXT NOT NULL REFERENCES accounts(id),
+	txn_id      TEXT NOT NULL UNIQUE,
+	amount      BIGINT NOT NULL,
+	asset       TEXT NOT NULL DEFAULT 'RUB',
+	timestamp   TIMESTAMPTZ NOT NULL,
+	counterparty TEXT NOT NULL DEFAULT ''
+);
+`
+
+// PostgresStorage persists accounts and their deposit/withdrawal ledgers in
+// Postgres. Every mutating call runs inside a single transaction so partial
+// writes are never visible to other connections.
+type PostgresStorage struct {
+	db *sql.DB
+}
+
+func postgresDSN() string {
+	return os.Getenv("BANK_POSTGRES_DSN")
+}
+
+func NewPostgresStorage(dsn string) (*PostgresStorage, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("подключение к postgres: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("postgres недоступен: %w", err)
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("применение схемы postgres: %w", err)
+	}
+	return &PostgresStorage{db: db}, nil
+}
+
+func (s *PostgresStorage) SaveAccount(account *Account) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if account.ID == "" {
+		if err := tx.QueryRow(`SELECT 'ACC' || lpad(nextval('accounts_id_seq')::text, 4, '0')`).Scan(&account.ID); err != nil {
+			return fmt.Errorf("генерация id счёта: %w", err)
+		}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO accounts (id, owner, currency, account_type, balance) VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (id) DO UPDATE SET owner = EXCLUDED.owner, currency = EXCLUDED.currency,
+			account_type = EXCLUDED.account_type, balance = EXCLUDED.balance`,
+		account.ID, account.Owner, account.Currency, string(account.Type), account.Balance,
+	); err != nil {
+		return err
+	}
+	if err := insertLedgerRows(tx, account); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertLedgerRows appends any new deposit/withdraw transactions from
+// account.History that are not yet recorded, keyed by their unique txn_id.
+func insertLedgerRows(tx *sql.Tx, account *Account) error {
+	for _, t := range account.History {
+		var table string
+		switch t.Type {
+		case "deposit":
+			table = "deposits"
+		case "withdraw":
+			table = "withdraws"
+		default:
+			continue
+		}
+		_, err := tx.Exec(
+			fmt.Sprintf(`INSERT INTO %s (account_id, txn_id, amount, asset, timestamp, counterparty)
+			 VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (txn_id) DO NOTHING`, table),
+			account.ID, t.ID, t.Amount, account.Currency, t.Timestamp, t.ToFrom,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PostgresStorage) LoadAccount(accountID string) (*Account, error) {
+	account := &Account{ID: accountID}
+	var accountType string
+	err := s.db.QueryRow(`SELECT owner, currency, account_type, balance FROM accounts WHERE id = $1`, accountID).
+		Scan(&account.Owner, &account.Currency, &accountType, &account.Balance)
+	account.Type = AccountType(accountType)
+	if err == sql.ErrNoRows {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.loadHistory(accountID)
+	if err != nil {
+		return nil, err
+	}
+	account.History = history
+	return account, nil
+}
+
+func (s *PostgresStorage) loadHistory(accountID string) ([]Transaction, error) {
+	var history []Transaction
+	for table, txnType := range map[string]string{"deposits": "deposit", "withdraws": "withdraw"} {
+		rows, err := s.db.Query(
+			fmt.Sprintf(`SELECT txn_id, amount, asset, timestamp, counterparty FROM %s WHERE account_id = $1 ORDER BY gid`, table),
+			accountID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var t Transaction
+			t.Type = txnType
+			if err := rows.Scan(&t.ID, &t.Amount, &t.Currency, &t.Timestamp, &t.ToFrom); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			history = append(history, t)
+		}
+		rows.Close()
+	}
+	return history, nil
+}
+
+func (s *PostgresStorage) GetAllAccounts() ([]*Account, error) {
+	rows, err := s.db.Query(`SELECT id FROM accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	accounts := make([]*Account, 0, len(ids))
+	for _, id := range ids {
+		acc, err := s.LoadAccount(id)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+func (s *PostgresStorage) DeleteAccount(accountID string) error {
+	res, err := s.db.Exec(`DELETE FROM accounts WHERE id = $1`, accountID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStorage) ListDeposits(accountID string) ([]Transaction, error) {
+	return s.listLedger(accountID, "deposits", "deposit")
+}
+
+func (s *PostgresStorage) ListWithdrawals(accountID string) ([]Transaction, error) {
+	return s.listLedger(accountID, "withdraws", "withdraw")
+}
+
+func (s *PostgresStorage) listLedger(accountID, table, txnType string) ([]Transaction, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(`SELECT txn_id, amount, asset, timestamp, counterparty FROM %s WHERE account_id = $1 ORDER BY gid`, table),
+		accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Transaction
+	for rows.Next() {
+		var t Transaction
+		t.Type = txnType
+		if err := rows.Scan(&t.ID, &t.Amount, &t.Currency, &t.Timestamp, &t.ToFrom); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestInMemoryStorageLoadAccountDoesNotAlias guards the bug where
+// LoadAccount handed back the live *Account stored in the map: mutating
+// the caller's copy would then mutate InMemoryStorage's own state.
+func TestInMemoryStorageLoadAccountDoesNotAlias(t *testing.T) {
+	s := NewInMemoryStorage()
+	account := &Account{Owner: "Alice", Currency: "USD", Type: Checking, Balance: 1000}
+	if err := s.SaveAccount(account); err != nil {
+		t.Fatalf("SaveAccount: %v", err)
+	}
+
+	loaded, err := s.LoadAccount(account.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount: %v", err)
+	}
+	loaded.Balance = 999999
+	loaded.History = append(loaded.History, Transaction{ID: "bogus"})
+
+	reloaded, err := s.LoadAccount(account.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount: %v", err)
+	}
+	if reloaded.Balance != 1000 {
+		t.Errorf("mutating a loaded *Account leaked into storage: Balance = %d, want 1000", reloaded.Balance)
+	}
+	if len(reloaded.History) != 0 {
+		t.Errorf("mutating a loaded *Account's History leaked into storage: len = %d, want 0", len(reloaded.History))
+	}
+}
+
+// TestBankingServiceConcurrentDeposit deposits into the same account from
+// many goroutines at once; run with -race, this is exactly the workload
+// concurrent gRPC Deposit calls against one account produce.
+func TestBankingServiceConcurrentDeposit(t *testing.T) {
+	storage := NewInMemoryStorage()
+	svc := NewBankingService(storage, StaticRateProvider{})
+
+	account, err := svc.OpenAccount("Bob", "USD", Checking, Money{})
+	if err != nil {
+		t.Fatalf("OpenAccount: %v", err)
+	}
+
+	const goroutines = 20
+	const depositsEach = 25
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < depositsEach; j++ {
+				if _, err := svc.Deposit(account.ID, Money{Amount: 100, Currency: "USD"}); err != nil {
+					t.Errorf("Deposit: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	final, err := storage.LoadAccount(account.ID)
+	if err != nil {
+		t.Fatalf("LoadAccount: %v", err)
+	}
+	want := int64(goroutines * depositsEach * 100)
+	if final.Balance != want {
+		t.Errorf("final balance = %d, want %d (lost update under concurrent Deposit)", final.Balance, want)
+	}
+	if len(final.History) != goroutines*depositsEach {
+		t.Errorf("final history length = %d, want %d", len(final.History), goroutines*depositsEach)
+	}
+}